@@ -3,12 +3,15 @@ package ast
 import (
 	"Ahmadi/token"
 	"bytes"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // where the node starts in the source
 }
 
 type Statement interface {
@@ -33,6 +36,13 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -51,6 +61,7 @@ type DefStatement struct {
 
 func (ds *DefStatement) statementNode()       {}
 func (ds *DefStatement) TokenLiteral() string { return ds.Token.Literal }
+func (ds *DefStatement) Pos() token.Position  { return ds.Token.Pos() }
 func (ds *DefStatement) String() string {
 	var out bytes.Buffer
 
@@ -73,6 +84,7 @@ type Identifier struct {
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos() }
 func (i *Identifier) String() string {
 	return i.Value
 }
@@ -84,6 +96,7 @@ type ReturnStatement struct {
 
 func (returnStatement *ReturnStatement) statementNode()       {}
 func (returnStatement *ReturnStatement) TokenLiteral() string { return returnStatement.Token.Literal }
+func (returnStatement *ReturnStatement) Pos() token.Position  { return returnStatement.Token.Pos() }
 func (returnStatement *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -97,6 +110,82 @@ func (returnStatement *ReturnStatement) String() string {
 	return out.String()
 }
 
+type WhileStatement struct {
+	Token     token.Token // while
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (whileStatement *WhileStatement) statementNode()       {}
+func (whileStatement *WhileStatement) TokenLiteral() string { return whileStatement.Token.Literal }
+func (whileStatement *WhileStatement) Pos() token.Position  { return whileStatement.Token.Pos() }
+func (whileStatement *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while (")
+	out.WriteString(whileStatement.Condition.String())
+	out.WriteString(") { ")
+	out.WriteString(whileStatement.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+// ForStatement iterates over a collection: `for (v in xs) { }` binds
+// each element to Value, and `for (k, v in xs) { }` also binds the key
+// or index to Key.
+type ForStatement struct {
+	Token    token.Token // for
+	Key      *Identifier
+	Value    *Identifier
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (forStatement *ForStatement) statementNode()       {}
+func (forStatement *ForStatement) TokenLiteral() string { return forStatement.Token.Literal }
+func (forStatement *ForStatement) Pos() token.Position  { return forStatement.Token.Pos() }
+func (forStatement *ForStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for (")
+	if forStatement.Key != nil {
+		out.WriteString(forStatement.Key.String() + ", ")
+	}
+	out.WriteString(forStatement.Value.String())
+	out.WriteString(" in ")
+	out.WriteString(forStatement.Iterable.String())
+	out.WriteString(") { ")
+	out.WriteString(forStatement.Body.String())
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+type BreakStatement struct {
+	Token token.Token // break
+}
+
+func (breakStatement *BreakStatement) statementNode()       {}
+func (breakStatement *BreakStatement) TokenLiteral() string { return breakStatement.Token.Literal }
+func (breakStatement *BreakStatement) Pos() token.Position  { return breakStatement.Token.Pos() }
+func (breakStatement *BreakStatement) String() string       { return breakStatement.TokenLiteral() + ";" }
+
+type ContinueStatement struct {
+	Token token.Token // continue
+}
+
+func (continueStatement *ContinueStatement) statementNode() {}
+func (continueStatement *ContinueStatement) TokenLiteral() string {
+	return continueStatement.Token.Literal
+}
+func (continueStatement *ContinueStatement) Pos() token.Position {
+	return continueStatement.Token.Pos()
+}
+func (continueStatement *ContinueStatement) String() string {
+	return continueStatement.TokenLiteral() + ";"
+}
+
 type ExpressionStatement struct {
 	Token      token.Token
 	Expression Expression
@@ -106,6 +195,9 @@ func (expressionStatement *ExpressionStatement) statementNode() {}
 func (expressionStatement *ExpressionStatement) TokenLiteral() string {
 	return expressionStatement.Token.Literal
 }
+func (expressionStatement *ExpressionStatement) Pos() token.Position {
+	return expressionStatement.Token.Pos()
+}
 func (expressionStatement *ExpressionStatement) String() string {
 	if expressionStatement.Expression != nil {
 		return expressionStatement.Expression.String()
@@ -116,12 +208,36 @@ func (expressionStatement *ExpressionStatement) String() string {
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	Big   *big.Int // set instead of Value when the literal needs more than 64 bits
 }
 
 func (integerLiteral *IntegerLiteral) expressionNode()      {}
 func (integerLiteral *IntegerLiteral) TokenLiteral() string { return integerLiteral.Token.Literal }
+func (integerLiteral *IntegerLiteral) Pos() token.Position  { return integerLiteral.Token.Pos() }
 func (integerLiteral *IntegerLiteral) String() string       { return integerLiteral.TokenLiteral() }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
+func (floatLiteral *FloatLiteral) expressionNode()      {}
+func (floatLiteral *FloatLiteral) TokenLiteral() string { return floatLiteral.Token.Literal }
+func (floatLiteral *FloatLiteral) Pos() token.Position  { return floatLiteral.Token.Pos() }
+func (floatLiteral *FloatLiteral) String() string       { return floatLiteral.TokenLiteral() }
+
+// CharLiteral is a character between single quotes, such as 'a'. Its
+// Token.Literal holds the character itself, with escapes decoded.
+type CharLiteral struct {
+	Token token.Token
+	Value rune
+}
+
+func (charLiteral *CharLiteral) expressionNode()      {}
+func (charLiteral *CharLiteral) TokenLiteral() string { return charLiteral.Token.Literal }
+func (charLiteral *CharLiteral) Pos() token.Position  { return charLiteral.Token.Pos() }
+func (charLiteral *CharLiteral) String() string       { return strconv.QuoteRune(charLiteral.Value) }
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -132,11 +248,17 @@ func (prefixExpression *PrefixExpression) expressionNode() {}
 func (prefixExpression *PrefixExpression) TokenLiteral() string {
 	return prefixExpression.Token.Literal
 }
+func (prefixExpression *PrefixExpression) Pos() token.Position {
+	return prefixExpression.Token.Pos()
+}
 func (prefixExpression *PrefixExpression) String() string {
 	var out bytes.Buffer
 
 	out.WriteString("(")
 	out.WriteString(prefixExpression.Operator)
+	if prefixExpression.Token.Type == token.NOT {
+		out.WriteString(" ")
+	}
 	out.WriteString(prefixExpression.Right.String())
 	out.WriteString(")")
 
@@ -154,6 +276,9 @@ func (infixExpression *InfixExpression) expressionNode() {}
 func (infixExpression *InfixExpression) TokenLiteral() string {
 	return infixExpression.Token.Literal
 }
+func (infixExpression *InfixExpression) Pos() token.Position {
+	return infixExpression.Left.Pos()
+}
 func (infixExpression *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -166,6 +291,34 @@ func (infixExpression *InfixExpression) String() string {
 	return out.String()
 }
 
+// AssignExpression rebinds an existing name, either plainly (`x = v`)
+// or through a compound operator such as `x += v`.
+type AssignExpression struct {
+	Token    token.Token // the assignment operator
+	Name     *Identifier
+	Operator string
+	Value    Expression
+}
+
+func (assignExpression *AssignExpression) expressionNode() {}
+func (assignExpression *AssignExpression) TokenLiteral() string {
+	return assignExpression.Token.Literal
+}
+func (assignExpression *AssignExpression) Pos() token.Position {
+	return assignExpression.Name.Pos()
+}
+func (assignExpression *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(assignExpression.Name.String())
+	out.WriteString(" " + assignExpression.Operator + " ")
+	out.WriteString(assignExpression.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -173,32 +326,62 @@ type Boolean struct {
 
 func (bl *Boolean) expressionNode()      {}
 func (bl *Boolean) TokenLiteral() string { return bl.Token.Literal }
+func (bl *Boolean) Pos() token.Position  { return bl.Token.Pos() }
 func (bl *Boolean) String() string       { return bl.TokenLiteral() }
 
 type IfExpression struct {
 	Token       token.Token
 	Condition   Expression
 	Consequence *BlockStatement
+	Elifs       []*ElifBranch
 	Alternative *BlockStatement
 }
 
 func (ifExpression *IfExpression) expressionNode()      {}
 func (ifExpression *IfExpression) TokenLiteral() string { return ifExpression.Token.Literal }
+func (ifExpression *IfExpression) Pos() token.Position  { return ifExpression.Token.Pos() }
 func (ifExpression *IfExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString("if")
+	out.WriteString("if (")
 	out.WriteString(ifExpression.Condition.String())
-	out.WriteString(" ")
+	out.WriteString(") { ")
 	out.WriteString(ifExpression.Consequence.String())
+	out.WriteString(" }")
+
+	for _, elif := range ifExpression.Elifs {
+		out.WriteString(" ")
+		out.WriteString(elif.String())
+	}
 
 	if ifExpression.Alternative != nil {
-		out.WriteString("else ")
+		out.WriteString(" else { ")
 		out.WriteString(ifExpression.Alternative.String())
+		out.WriteString(" }")
 	}
 	return out.String()
 }
 
+// ElifBranch is one `elif (condition) { ... }` arm of an IfExpression.
+type ElifBranch struct {
+	Token       token.Token // elif
+	Condition   Expression
+	Consequence *BlockStatement
+}
+
+func (elifBranch *ElifBranch) TokenLiteral() string { return elifBranch.Token.Literal }
+func (elifBranch *ElifBranch) Pos() token.Position  { return elifBranch.Token.Pos() }
+func (elifBranch *ElifBranch) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("elif (")
+	out.WriteString(elifBranch.Condition.String())
+	out.WriteString(") { ")
+	out.WriteString(elifBranch.Consequence.String())
+	out.WriteString(" }")
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
@@ -206,6 +389,7 @@ type BlockStatement struct {
 
 func (blockStatement *BlockStatement) statementNode()       {}
 func (blockStatement *BlockStatement) TokenLiteral() string { return blockStatement.Token.Literal }
+func (blockStatement *BlockStatement) Pos() token.Position  { return blockStatement.Token.Pos() }
 func (blockStatement *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -223,6 +407,7 @@ type FunctionLiteral struct {
 
 func (functionLiteral *FunctionLiteral) expressionNode()      {}
 func (functionLiteral *FunctionLiteral) TokenLiteral() string { return functionLiteral.Token.Literal }
+func (functionLiteral *FunctionLiteral) Pos() token.Position  { return functionLiteral.Token.Pos() }
 func (functionLiteral *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
@@ -248,6 +433,9 @@ type CallExpression struct {
 
 func (callExpression *CallExpression) expressionNode()      {}
 func (callExpression *CallExpression) TokenLiteral() string { return callExpression.Token.Literal }
+func (callExpression *CallExpression) Pos() token.Position {
+	return callExpression.Function.Pos()
+}
 func (callExpression *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -271,8 +459,38 @@ type StringLiteral struct {
 
 func (stringLiteral *StringLiteral) expressionNode()      {}
 func (stringLiteral *StringLiteral) TokenLiteral() string { return stringLiteral.Token.Literal }
+func (stringLiteral *StringLiteral) Pos() token.Position  { return stringLiteral.Token.Pos() }
 func (stringLiteral *StringLiteral) String() string       { return stringLiteral.TokenLiteral() }
 
+// InterpolatedString is a string literal with embedded expressions, as
+// in "Hello ${name}!". Parts holds the text segments as StringLiterals,
+// with the expressions between them.
+type InterpolatedString struct {
+	Token token.Token // STRING_START
+	Parts []Expression
+}
+
+func (interpolatedString *InterpolatedString) expressionNode() {}
+func (interpolatedString *InterpolatedString) TokenLiteral() string {
+	return interpolatedString.Token.Literal
+}
+func (interpolatedString *InterpolatedString) Pos() token.Position {
+	return interpolatedString.Token.Pos()
+}
+func (interpolatedString *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	for _, part := range interpolatedString.Parts {
+		if literal, ok := part.(*StringLiteral); ok {
+			out.WriteString(literal.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+
+	return out.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
@@ -280,6 +498,7 @@ type ArrayLiteral struct {
 
 func (arrayLiteral *ArrayLiteral) expressionNode()      {}
 func (arrayLiteral *ArrayLiteral) TokenLiteral() string { return arrayLiteral.Token.Literal }
+func (arrayLiteral *ArrayLiteral) Pos() token.Position  { return arrayLiteral.Token.Pos() }
 func (arrayLiteral *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -304,6 +523,9 @@ type IndexExpression struct {
 
 func (indexExpression *IndexExpression) expressionNode()      {}
 func (indexExpression *IndexExpression) TokenLiteral() string { return indexExpression.Token.Literal }
+func (indexExpression *IndexExpression) Pos() token.Position {
+	return indexExpression.Left.Pos()
+}
 func (indexExpression *IndexExpression) String() string {
 	var out bytes.Buffer
 
@@ -323,6 +545,7 @@ type HashLiteral struct {
 
 func (hashLiteral *HashLiteral) expressionNode()      {}
 func (hashLiteral *HashLiteral) TokenLiteral() string { return hashLiteral.Token.Literal }
+func (hashLiteral *HashLiteral) Pos() token.Position  { return hashLiteral.Token.Pos() }
 func (hashLiteral *HashLiteral) String() string {
 	var out bytes.Buffer
 
@@ -1,10 +1,21 @@
 package evaluator
 
 import (
+	"Ahmadi/lexer"
 	"Ahmadi/object"
 	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
+// MaxRangeLength bounds the arrays built by range, which are held in
+// memory all at once. Longer counts are better served by a while loop.
+const MaxRangeLength = 10_000_000
+
 var builtins = map[string]*object.Builtin{
 	"len": {
 		Fn: func(args ...object.Object) object.Object {
@@ -15,7 +26,7 @@ var builtins = map[string]*object.Builtin{
 			switch arg := args[0].(type) {
 			case *object.String:
 				return &object.Integer{
-					Value: int64(len(arg.Value)),
+					Value: int64(utf8.RuneCountInString(arg.Value)),
 				}
 
 			case *object.Array:
@@ -181,6 +192,147 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 
+	"range": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments to 'range' function. got=%d, want=1, 2 or 3", len(args))
+			}
+
+			bounds := make([]int64, len(args))
+			for index, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("arguments to 'range' must be INTEGER. got %s", arg.Type())
+				}
+				bounds[index] = integer.Value
+			}
+
+			// range(end), range(start, end) or range(start, end, step)
+			start, end, step := int64(0), bounds[0], int64(1)
+			if len(bounds) > 1 {
+				start, end = bounds[0], bounds[1]
+			}
+			if len(bounds) > 2 {
+				step = bounds[2]
+			}
+
+			if step == 0 {
+				return newError("step of 'range' must not be zero")
+			}
+
+			length := rangeLength(start, end, step)
+			if length > MaxRangeLength {
+				return newError("'range' would produce %d elements, more than the limit of %d", length, MaxRangeLength)
+			}
+
+			elements := make([]object.Object, length)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+
+			return &object.Array{
+				Elements: elements,
+			}
+		},
+	},
+
+	"int": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments to 'int' function. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+
+			case *object.BigInt:
+				return arg
+
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) {
+					return newError("cannot convert %s to INTEGER", arg.Inspect())
+				}
+				value, _ := new(big.Float).SetFloat64(arg.Value).Int(nil)
+				return bigIntToObject(value)
+
+			case *object.String:
+				value, ok := lexer.ParseInteger(strings.TrimSpace(arg.Value))
+				if !ok {
+					return newError("cannot convert %q to INTEGER", arg.Value)
+				}
+				return bigIntToObject(value)
+
+			default:
+				return newError("argument to 'int' not supported, got %s", arg.Type())
+			}
+		},
+	},
+
+	"float": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments to 'float' function. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Float:
+				return arg
+
+			case *object.Integer, *object.BigInt:
+				return &object.Float{
+					Value: toFloat(arg),
+				}
+
+			case *object.String:
+				value, err := strconv.ParseFloat(strings.TrimSpace(arg.Value), 64)
+				if err != nil {
+					return newError("cannot convert %q to FLOAT", arg.Value)
+				}
+				return &object.Float{
+					Value: value,
+				}
+
+			default:
+				return newError("argument to 'float' not supported, got %s", arg.Type())
+			}
+		},
+	},
+
+	"str": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments to 'str' function. got=%d, want=1", len(args))
+			}
+
+			return &object.String{
+				Value: args[0].Inspect(),
+			}
+		},
+	},
+
+	"bytes": {
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments to 'bytes' function. got=%d, want=1", len(args))
+			}
+
+			str, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to 'bytes' must be STRING, got %s", args[0].Type())
+			}
+
+			elements := make([]object.Object, 0, len(str.Value))
+			for i := 0; i < len(str.Value); i++ {
+				elements = append(elements, &object.Integer{Value: int64(str.Value[i])})
+			}
+
+			return &object.Array{
+				Elements: elements,
+			}
+		},
+	},
+
 	"echo": {
 		Fn: func(args ...object.Object) object.Object {
 			for _, arg := range args {
@@ -193,3 +345,26 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 }
+
+// BuiltinNames returns the names of the builtin functions, sorted.
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rangeLength counts the values range(start, end, step) produces, without
+// overflowing when the bounds are far apart.
+func rangeLength(start int64, end int64, step int64) uint64 {
+	switch {
+	case step > 0 && start < end:
+		return (uint64(end)-uint64(start)-1)/uint64(step) + 1
+	case step < 0 && start > end:
+		return (uint64(start)-uint64(end)-1)/(-uint64(step)) + 1
+	default:
+		return 0
+	}
+}
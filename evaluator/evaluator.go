@@ -3,9 +3,27 @@ package evaluator
 import (
 	"Ahmadi/ast"
 	"Ahmadi/object"
+	"Ahmadi/token"
 	"fmt"
+	"math"
+	"math/big"
+	"strings"
 )
 
+// MaxCallDepth bounds the nesting of function calls, so that runaway
+// recursion becomes an APL error instead of exhausting the Go stack.
+const MaxCallDepth = 10000
+
+// MaxNestingDepth bounds how deeply evaluations nest, counting every
+// expression and block on the way, as a single call can nest many of
+// them. It keeps the Go stack well below its limit, since overflowing
+// it is fatal and cannot be recovered by SafeEval.
+const MaxNestingDepth = 100000
+
+// MaxIntegerBits bounds the size of the results of ** and <<, whose
+// operands can ask for more memory or time than any host has.
+const MaxIntegerBits = 1 << 20
+
 var (
 	TRUE = &object.Boolean{
 		Value: true,
@@ -13,10 +31,41 @@ var (
 	FALSE = &object.Boolean{
 		Value: false,
 	}
-	NULL = &object.Null{}
+	NULL     = &object.Null{}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
+// SafeEval evaluates node like Eval, but turns a Go panic raised while
+// evaluating into an APL error so that a script cannot crash its host.
+func SafeEval(node ast.Node, env *object.Environment) (result object.Object) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = newError("internal error: %v", r)
+		}
+	}()
+
+	return Eval(node, env)
+}
+
+// Eval evaluates node in env. An error raised while evaluating node
+// that does not carry a position yet is attributed to node.
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	defer env.Leave()
+	if env.Enter() > MaxNestingDepth {
+		return newError("maximum nesting depth of %d exceeded", MaxNestingDepth)
+	}
+
+	result := evalNode(node, env)
+
+	if err, ok := result.(*object.Error); ok && err.Position.Line == 0 && node != nil {
+		err.Position = node.Pos()
+	}
+
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	// Statements
@@ -29,10 +78,25 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Expressions
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return bigIntToObject(node.Big)
+		}
 		return &object.Integer{
 			Value: node.Value,
 		}
 
+	// Float Literal
+	case *ast.FloatLiteral:
+		return &object.Float{
+			Value: node.Value,
+		}
+
+	// Char Literal
+	case *ast.CharLiteral:
+		return &object.Integer{
+			Value: int64(node.Value),
+		}
+
 	// Boolean
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
@@ -47,6 +111,10 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	// Infix Expression
 	case *ast.InfixExpression:
+		if isLogicalOperator(node.Operator) {
+			return evalLogicalExpression(node, env)
+		}
+
 		left := Eval(node.Left, env)
 
 		if isError(left) {
@@ -79,14 +147,37 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			Value: val,
 		}
 
+	// While Statement
+	case *ast.WhileStatement:
+		return evalWhileStatement(node, env)
+
+	// For Statement
+	case *ast.ForStatement:
+		return evalForStatement(node, env)
+
+	// Break Statement
+	case *ast.BreakStatement:
+		return BREAK
+
+	// Continue Statement
+	case *ast.ContinueStatement:
+		return CONTINUE
+
 	// Def Statement
 	case *ast.DefStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
+		if function, ok := val.(*object.Function); ok && function.Name == "" {
+			function.Name = node.Name.Value
+		}
 		env.Set(node.Name.Value, val)
 
+	// Assign Expression
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+
 	// Identifier
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
@@ -109,7 +200,7 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		if len(args) == 1 && isError(args[0]) {
 			return args[0]
 		}
-		return applyFunction(function, args)
+		return applyFunction(function, args, env, node.Pos())
 
 	// String Literal
 	case *ast.StringLiteral:
@@ -117,6 +208,9 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 			Value: node.Value,
 		}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+
 	// Array Literal
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
@@ -182,6 +276,9 @@ func evalIndexExpression(left object.Object, index object.Object) object.Object
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
 
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 
@@ -217,12 +314,42 @@ func evalArrayIndexExpression(array object.Object, index object.Object) object.O
 	return arrayObject.Elements[idx]
 }
 
-func applyFunction(fun object.Object, args []object.Object) object.Object {
+// evalStringIndexExpression returns the character at index as a string.
+// Strings are indexed by character, not by byte.
+func evalStringIndexExpression(str object.Object, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+	max := int64(len(runes) - 1)
+
+	if idx < 0 || idx > max {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
+func applyFunction(
+	fun object.Object,
+	args []object.Object,
+	caller *object.Environment,
+	callSite token.Position,
+) object.Object {
 
 	switch fun := fun.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fun, args)
+		if caller.Depth() >= MaxCallDepth {
+			return newError("maximum call depth of %d exceeded", MaxCallDepth)
+		}
+
+		extendedEnv, err := extendFunctionEnv(fun, args, caller)
+		if err != nil {
+			return err
+		}
+
 		evaluated := Eval(fun.Body, extendedEnv)
+		if err, ok := evaluated.(*object.Error); ok {
+			err.AddFrame(object.Frame{Function: fun.Name, Position: callSite})
+		}
 		return unwrapReturnValue(evaluated)
 
 	case *object.Builtin:
@@ -244,14 +371,22 @@ func unwrapReturnValue(obj object.Object) object.Object {
 func extendFunctionEnv(
 	fun *object.Function,
 	args []object.Object,
-) *object.Environment {
-	env := object.NewEncloseEnvironment(fun.Env)
+	caller *object.Environment,
+) (*object.Environment, *object.Error) {
+	switch {
+	case len(args) < len(fun.Parameters):
+		return nil, newError("too few arguments. got=%d, want=%d", len(args), len(fun.Parameters))
+	case len(args) > len(fun.Parameters):
+		return nil, newError("too many arguments. got=%d, want=%d", len(args), len(fun.Parameters))
+	}
+
+	env := object.NewCallEnvironment(fun.Env, caller)
 
 	for index, param := range fun.Parameters {
 		env.Set(param.Value, args[index])
 	}
 
-	return env
+	return env, nil
 }
 
 func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Object {
@@ -269,6 +404,30 @@ func evalExpressions(exps []ast.Expression, env *object.Environment) []object.Ob
 	return result
 }
 
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	name := node.Name.Value
+	current, ok := env.Get(name)
+	if !ok {
+		return newError("cannot assign to undefined identifier: %s", name)
+	}
+
+	val := Eval(node.Value, env)
+	if isError(val) {
+		return val
+	}
+
+	if node.Operator != "=" {
+		// x += v is x = x + v
+		val = evalInfixExpression(strings.TrimSuffix(node.Operator, "="), current, val)
+		if isError(val) {
+			return val
+		}
+	}
+
+	env.Assign(name, val)
+	return val
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 
 	if val, ok := env.Get(node.Value); ok {
@@ -290,7 +449,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 
 		if result != nil {
 			resultType := result.Type()
-			if resultType == object.RETURN_VALUE_OBJ || resultType == object.ERROR_OBJ {
+			switch resultType {
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
 				return result
 			}
 		}
@@ -325,11 +485,131 @@ func evalIfExpression(ifExpression *ast.IfExpression, env *object.Environment) o
 
 	if isTruthy(condition) {
 		return Eval(ifExpression.Consequence, env)
-	} else if ifExpression.Alternative != nil {
+	}
+
+	for _, elif := range ifExpression.Elifs {
+		condition := Eval(elif.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if isTruthy(condition) {
+			return Eval(elif.Consequence, env)
+		}
+	}
+
+	if ifExpression.Alternative != nil {
 		return Eval(ifExpression.Alternative, env)
-	} else {
-		return NULL
 	}
+	return NULL
+}
+
+func evalWhileStatement(whileStatement *ast.WhileStatement, env *object.Environment) object.Object {
+	for {
+		condition := Eval(whileStatement.Condition, env)
+		if isError(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, done := evalLoopBody(whileStatement.Body, env); done {
+			return result
+		}
+	}
+}
+
+func evalForStatement(forStatement *ast.ForStatement, env *object.Environment) object.Object {
+	iterable := Eval(forStatement.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+
+	var result object.Object = NULL
+	ok := iterate(iterable, func(key object.Object, value object.Object) bool {
+		// every iteration gets its own scope so closures capture the
+		// element they were created for
+		iterationEnv := object.NewEncloseEnvironment(env)
+		if forStatement.Key != nil {
+			iterationEnv.Set(forStatement.Key.Value, key)
+			iterationEnv.Set(forStatement.Value.Value, value)
+		} else if iterable.Type() == object.HASH_OBJ {
+			iterationEnv.Set(forStatement.Value.Value, key)
+		} else {
+			iterationEnv.Set(forStatement.Value.Value, value)
+		}
+
+		if stopResult, done := evalLoopBody(forStatement.Body, iterationEnv); done {
+			result = stopResult
+			return false
+		}
+		return true
+	})
+	if !ok {
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	return result
+}
+
+// iterate calls visit with what a for loop visits, until visit returns
+// false: index and element for arrays, index and character for strings,
+// key and value for hashes. A single loop variable over a hash receives
+// the key. It reports false when iterable cannot be iterated.
+func iterate(iterable object.Object, visit func(key object.Object, value object.Object) bool) bool {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		for index, element := range iterable.Elements {
+			if !visit(&object.Integer{Value: int64(index)}, element) {
+				break
+			}
+		}
+
+	case *object.String:
+		index := 0
+		for _, ch := range iterable.Value {
+			if !visit(&object.Integer{Value: int64(index)}, &object.String{Value: string(ch)}) {
+				break
+			}
+			index++
+		}
+
+	case *object.Hash:
+		for _, pair := range iterable.SortedPairs() {
+			if !visit(pair.Key, pair.Value) {
+				break
+			}
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// evalLoopBody runs one iteration of a loop body. It reports whether the
+// loop has to stop, and if so the value the loop statement evaluates to.
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (object.Object, bool) {
+	result := Eval(body, env)
+
+	switch result {
+	case BREAK:
+		return NULL, true
+	case CONTINUE:
+		return nil, false
+	}
+
+	if result != nil {
+		resultType := result.Type()
+		if resultType == object.RETURN_VALUE_OBJ || resultType == object.ERROR_OBJ {
+			return result, true
+		}
+	}
+
+	return nil, false
 }
 
 func isTruthy(obj object.Object) bool {
@@ -349,6 +629,37 @@ func isTruthy(obj object.Object) bool {
 	}
 }
 
+func isLogicalOperator(operator string) bool {
+	switch operator {
+	case "&&", "and", "||", "or":
+		return true
+	}
+	return false
+}
+
+// evalLogicalExpression short-circuits: the right operand is only
+// evaluated when the left one does not decide the result. The deciding
+// operand itself is returned, not a boolean.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	switch node.Operator {
+	case "&&", "and":
+		if !isTruthy(left) {
+			return left
+		}
+	case "||", "or":
+		if isTruthy(left) {
+			return left
+		}
+	}
+
+	return Eval(node.Right, env)
+}
+
 func evalInfixExpression(
 	operator string,
 	left object.Object,
@@ -358,10 +669,16 @@ func evalInfixExpression(
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
 
+	case isInteger(left) && isInteger(right):
+		return evalBigIntInfixExpression(operator, left, right)
+
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
+
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 
-	case operator == "!=":
+	case operator == "!=" || operator == "<>":
 		return nativeBoolToBooleanObject(left != right)
 
 	case left.Type() != right.Type():
@@ -394,24 +711,298 @@ func evalIntegerInfixExpression(
 
 	switch operator {
 	case "+":
+		sum := leftVal + rightVal
+		if (leftVal > 0 && rightVal > 0 && sum < 0) || (leftVal < 0 && rightVal < 0 && sum >= 0) {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
 		return &object.Integer{
-			Value: leftVal + rightVal,
+			Value: sum,
 		}
 
 	case "-":
+		difference := leftVal - rightVal
+		if (leftVal >= 0 && rightVal < 0 && difference < 0) || (leftVal < 0 && rightVal > 0 && difference >= 0) {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
 		return &object.Integer{
-			Value: leftVal - rightVal,
+			Value: difference,
 		}
 
 	case "*":
+		product := leftVal * rightVal
+		if leftVal != 0 && (product/leftVal != rightVal || (leftVal == -1 && rightVal == math.MinInt64)) {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
+		return &object.Integer{
+			Value: product,
+		}
+
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		if leftVal == math.MinInt64 && rightVal == -1 {
+			return evalBigIntInfixExpression(operator, left, right)
+		}
+		quotient := leftVal / rightVal
+		if leftVal%rightVal != 0 && (leftVal < 0) != (rightVal < 0) {
+			quotient--
+		}
+		return &object.Integer{
+			Value: quotient,
+		}
+
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		remainder := leftVal % rightVal
+		if remainder != 0 && (remainder < 0) != (rightVal < 0) {
+			remainder += rightVal
+		}
+		return &object.Integer{
+			Value: remainder,
+		}
+
+	case "&":
+		return &object.Integer{
+			Value: leftVal & rightVal,
+		}
+
+	case "|":
+		return &object.Integer{
+			Value: leftVal | rightVal,
+		}
+
+	case "^":
 		return &object.Integer{
-			Value: leftVal * rightVal,
+			Value: leftVal ^ rightVal,
 		}
 
+	case ">>":
+		if rightVal < 0 {
+			return newError("negative shift count: %d", rightVal)
+		}
+		return &object.Integer{
+			Value: leftVal >> min(rightVal, 63),
+		}
+
+	case "**", "<<":
+		return evalBigIntInfixExpression(operator, left, right)
+
+	case ">":
+		return nativeBoolToBooleanObject(leftVal > rightVal)
+
+	case "<":
+		return nativeBoolToBooleanObject(leftVal < rightVal)
+
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+
+	case "==":
+		return nativeBoolToBooleanObject(leftVal == rightVal)
+
+	case "!=", "<>":
+		return nativeBoolToBooleanObject(leftVal != rightVal)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalBigIntInfixExpression does integer arithmetic in arbitrary
+// precision. It is used when an operand is a BigInt or when int64
+// arithmetic would overflow; results that fit are demoted to Integer.
+func evalBigIntInfixExpression(
+	operator string,
+	left object.Object,
+	right object.Object,
+) object.Object {
+	leftVal := toBigInt(left)
+	rightVal := toBigInt(right)
+
+	switch operator {
+	case "+":
+		return bigIntToObject(new(big.Int).Add(leftVal, rightVal))
+
+	case "-":
+		return bigIntToObject(new(big.Int).Sub(leftVal, rightVal))
+
+	case "*":
+		return bigIntToObject(new(big.Int).Mul(leftVal, rightVal))
+
 	case "/":
+		if rightVal.Sign() == 0 {
+			return newError("division by zero")
+		}
+		quotient, _ := floorDivision(leftVal, rightVal)
+		return bigIntToObject(quotient)
+
+	case "%":
+		if rightVal.Sign() == 0 {
+			return newError("modulo by zero")
+		}
+		_, remainder := floorDivision(leftVal, rightVal)
+		return bigIntToObject(remainder)
+
+	case "**":
+		if rightVal.Sign() < 0 {
+			if leftVal.Sign() == 0 {
+				return newError("division by zero")
+			}
+			return &object.Float{Value: math.Pow(toFloat(left), toFloat(right))}
+		}
+		if !powerFits(leftVal, rightVal) {
+			return newError("integer overflow: result of ** would exceed %d bits", MaxIntegerBits)
+		}
+		return bigIntToObject(new(big.Int).Exp(leftVal, rightVal, nil))
+
+	case "&":
+		return bigIntToObject(new(big.Int).And(leftVal, rightVal))
+
+	case "|":
+		return bigIntToObject(new(big.Int).Or(leftVal, rightVal))
+
+	case "^":
+		return bigIntToObject(new(big.Int).Xor(leftVal, rightVal))
+
+	case "<<", ">>":
+		if rightVal.Sign() < 0 {
+			return newError("negative shift count: %s", rightVal)
+		}
+		if operator == ">>" {
+			if !rightVal.IsInt64() || rightVal.Int64() > int64(leftVal.BitLen()) {
+				// every bit is shifted out, leaving only the sign
+				return bigIntToObject(big.NewInt(int64(min(leftVal.Sign(), 0))))
+			}
+			return bigIntToObject(new(big.Int).Rsh(leftVal, uint(rightVal.Int64())))
+		}
+		if !shiftFits(leftVal, rightVal) {
+			return newError("integer overflow: result of << would exceed %d bits", MaxIntegerBits)
+		}
+		return bigIntToObject(new(big.Int).Lsh(leftVal, uint(rightVal.Int64())))
+
+	case ">":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) > 0)
+
+	case "<":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) < 0)
+
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) >= 0)
+
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) <= 0)
+
+	case "==":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) == 0)
+
+	case "!=", "<>":
+		return nativeBoolToBooleanObject(leftVal.Cmp(rightVal) != 0)
+
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// powerFits reports whether base ** exponent stays within MaxIntegerBits.
+func powerFits(base *big.Int, exponent *big.Int) bool {
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		return true
+	}
+	return exponent.IsInt64() && exponent.Int64() <= MaxIntegerBits/int64(base.BitLen())
+}
+
+// shiftFits reports whether value << count stays within MaxIntegerBits.
+func shiftFits(value *big.Int, count *big.Int) bool {
+	if value.Sign() == 0 {
+		return true
+	}
+	return count.IsInt64() && count.Int64() <= MaxIntegerBits-int64(value.BitLen())
+}
+
+// floorDivision divides x by y rounding the quotient down, so that the
+// remainder takes the sign of y: -7 / 2 is -4 and -7 % 2 is 1.
+func floorDivision(x *big.Int, y *big.Int) (*big.Int, *big.Int) {
+	quotient, remainder := new(big.Int).QuoRem(x, y, new(big.Int))
+	if remainder.Sign() != 0 && remainder.Sign() != y.Sign() {
+		quotient.Sub(quotient, big.NewInt(1))
+		remainder.Add(remainder, y)
+	}
+	return quotient, remainder
+}
+
+func isInteger(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.BIGINT_OBJ
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInt:
+		return obj.Value
+	default:
+		return new(big.Int)
+	}
+}
+
+// bigIntToObject demotes value to an Integer when it fits in 64 bits.
+func bigIntToObject(value *big.Int) object.Object {
+	if value.IsInt64() {
 		return &object.Integer{
-			Value: leftVal / rightVal,
+			Value: value.Int64(),
+		}
+	}
+	return &object.BigInt{
+		Value: value,
+	}
+}
+
+// evalFloatInfixExpression handles arithmetic and comparison when at
+// least one operand is a float; an integer operand is widened first.
+func evalFloatInfixExpression(
+	operator string,
+	left object.Object,
+	right object.Object,
+) object.Object {
+	leftVal := toFloat(left)
+	rightVal := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftVal + rightVal}
+
+	case "-":
+		return &object.Float{Value: leftVal - rightVal}
+
+	case "*":
+		return &object.Float{Value: leftVal * rightVal}
+
+	case "/":
+		if rightVal == 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: leftVal / rightVal}
+
+	case "%":
+		if rightVal == 0 {
+			return newError("modulo by zero")
+		}
+		remainder := math.Mod(leftVal, rightVal)
+		if remainder != 0 && (remainder < 0) != (rightVal < 0) {
+			remainder += rightVal
 		}
+		return &object.Float{Value: remainder}
+
+	case "**":
+		if leftVal == 0 && rightVal < 0 {
+			return newError("division by zero")
+		}
+		return &object.Float{Value: math.Pow(leftVal, rightVal)}
 
 	case ">":
 		return nativeBoolToBooleanObject(leftVal > rightVal)
@@ -419,10 +1010,16 @@ func evalIntegerInfixExpression(
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 
+	case ">=":
+		return nativeBoolToBooleanObject(leftVal >= rightVal)
+
+	case "<=":
+		return nativeBoolToBooleanObject(leftVal <= rightVal)
+
 	case "==":
 		return nativeBoolToBooleanObject(leftVal == rightVal)
 
-	case "!=":
+	case "!=", "<>":
 		return nativeBoolToBooleanObject(leftVal != rightVal)
 
 	default:
@@ -430,6 +1027,24 @@ func evalIntegerInfixExpression(
 	}
 }
 
+func isNumber(obj object.Object) bool {
+	return isInteger(obj) || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInt:
+		value, _ := new(big.Float).SetInt(obj.Value).Float64()
+		return value
+	case *object.Float:
+		return obj.Value
+	default:
+		return 0
+	}
+}
+
 // func evalStatements(statements []ast.Statement, env *object.Environment) object.Object {
 // 	var result object.Object
 
@@ -453,10 +1068,12 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 
 func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
-	case "!":
+	case "!", "not":
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalMinusPrefixOperatorExpression(right)
+	case "~":
+		return evalBitwiseNotOperatorExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right.Type())
 	}
@@ -476,12 +1093,40 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusPrefixOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		if right.Value == math.MinInt64 {
+			return bigIntToObject(new(big.Int).Neg(big.NewInt(right.Value)))
+		}
+		return &object.Integer{
+			Value: -right.Value,
+		}
+
+	case *object.BigInt:
+		return bigIntToObject(new(big.Int).Neg(right.Value))
+
+	case *object.Float:
+		return &object.Float{
+			Value: -right.Value,
+		}
+
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-	value := right.(*object.Integer).Value
-	return &object.Integer{
-		Value: -value,
+}
+
+func evalBitwiseNotOperatorExpression(right object.Object) object.Object {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{
+			Value: ^right.Value,
+		}
+
+	case *object.BigInt:
+		return bigIntToObject(new(big.Int).Not(right.Value))
+
+	default:
+		return newError("unknown operator: ~%s", right.Type())
 	}
 }
 
@@ -498,3 +1143,24 @@ func isError(obj object.Object) bool {
 
 	return false
 }
+
+// evalInterpolatedString joins the text segments of node with the
+// values of its embedded expressions, written as Inspect shows them.
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		if literal, ok := part.(*ast.StringLiteral); ok {
+			out.WriteString(literal.Value)
+			continue
+		}
+
+		value := Eval(part, env)
+		if isError(value) {
+			return value
+		}
+		out.WriteString(value.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
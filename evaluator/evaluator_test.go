@@ -1,9 +1,11 @@
 package evaluator
 
 import (
+	"Ahmadi/ast"
 	"Ahmadi/lexer"
 	"Ahmadi/object"
 	"Ahmadi/parser"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +47,21 @@ func testEval(input string) object.Object {
 	return Eval(program, object.NewEnvironment())
 }
 
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+	if !ok {
+		t.Errorf("object is not Float. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+
+	return true
+}
+
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	result, ok := obj.(*object.Integer)
 	if !ok {
@@ -60,6 +77,162 @@ func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 	return true
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{".5", 0.5},
+		{"1e3", 1000},
+		{"2.5e-1", 0.25},
+		{"-1.5", -1.5},
+		{"7.0 / 2", 3.5},
+		{"7 / 2.0", 3.5},
+		{"1.5 + 1", 2.5},
+		{"1 - 0.25", 0.75},
+		{"2 * 0.5 * 4", 4},
+		{"-(0.5 + 0.25)", -0.75},
+		{"float(7) / 2", 3.5},
+		{`float("2.5")`, 2.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			testFloatObject(t, testEval(test.input), test.expected)
+		})
+	}
+}
+
+func TestFloatComparisonAndConversion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1.5 > 1", true},
+		{"1 < 1.5", true},
+		{"2.0 == 2", true},
+		{"2.0 != 2", false},
+		{"0.1 + 0.2 >= 0.3", true},
+		{"1.5 <= 1.4", false},
+		{"int(3.99)", 3},
+		{"int(-3.99)", -3},
+		{`int("42")`, 42},
+		{`int("010")`, 10},
+		{`int("010") == 010`, true},
+		{`int(" -7 ")`, -7},
+		{`int("0x1F")`, 31},
+		{`int("-0b101")`, -5},
+		{`int("+0o17")`, 15},
+		{`int("1_000")`, 1000},
+		{`str(1.0) + "!"`, "1.0!"},
+		{`str(0.5)`, "0.5"},
+		{`str(42)`, "42"},
+		{`{1.5: "a", 2: "b"}[1.5]`, "a"},
+		{`{0.0: "zero"}[-0.0]`, "zero"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			switch expected := test.expected.(type) {
+			case bool:
+				testBoolObject(t, evaluated, expected)
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				str, ok := evaluated.(*object.String)
+				if !ok || str.Value != expected {
+					t.Errorf("expected String %q. got=%T (%+v)", expected, evaluated, evaluated)
+				}
+			}
+		})
+	}
+}
+
+func TestBigIntPromotion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"4294967296 * 4294967296", "18446744073709551616"},
+		{"-9223372036854775808 / -1", "9223372036854775808"},
+		{"-(-9223372036854775808)", "9223372036854775808"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 * 10 + 5", "1234567890123456789012345678905"},
+		{`int("99999999999999999999")`, "99999999999999999999"},
+		{
+			"def fact = fun(n) { if (n < 2) { return 1; } n * fact(n - 1) }; fact(30);",
+			"265252859812191058636308480000000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			result, ok := evaluated.(*object.BigInt)
+			if !ok {
+				t.Fatalf("object is not BigInt. got=%T (%+v)", evaluated, evaluated)
+			}
+
+			if result.Inspect() != test.expected {
+				t.Errorf("object has wrong value. got=%s, want=%s", result.Inspect(), test.expected)
+			}
+		})
+	}
+}
+
+func TestBigIntDemotion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"9223372036854775807 + 1 - 1", 9223372036854775807},
+		{"(9223372036854775807 + 10) / 10", 922337203685477581},
+		{"-9223372036854775808", -9223372036854775808},
+		{"9223372036854775808 - 9223372036854775808", 0},
+		{"9223372036854775808 > 9223372036854775807", true},
+		{"9223372036854775808 == 9223372036854775807 + 1", true},
+		{"9223372036854775808 < 1.5", false},
+		{`{9223372036854775808: "big"}[9223372036854775807 + 1]`, "big"},
+		{"9223372036854775808 / 0", "division by zero"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case bool:
+				testBoolObject(t, evaluated, expected)
+			case string:
+				switch evaluated := evaluated.(type) {
+				case *object.String:
+					if evaluated.Value != expected {
+						t.Errorf("String has wrong value. expected=%q, got=%q", expected, evaluated.Value)
+					}
+				case *object.Error:
+					if evaluated.Message != expected {
+						t.Errorf("wrong error message. expected=%q, got=%q", expected, evaluated.Message)
+					}
+				default:
+					t.Errorf("object is not String or Error. got=%T (%+v)", evaluated, evaluated)
+				}
+			}
+		})
+	}
+}
+
 func TestEvalBooleanExpression(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -85,6 +258,17 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == false", false},
 		{"(1 > 2) == true", false},
 		{"(1 > 2) == false", true},
+		{"1 >= 1", true},
+		{"1 >= 2", false},
+		{"2 >= 1", true},
+		{"1 <= 1", true},
+		{"2 <= 1", false},
+		{"1 <= 2", true},
+		{"1 <> 2", true},
+		{"1 <> 1", false},
+		{"true <> false", true},
+		{"true <> true", false},
+		{"(1 <= 2) == (2 >= 1)", true},
 	}
 
 	for _, test := range tests {
@@ -142,6 +326,11 @@ func TestIfElseExpressions(t *testing.T) {
 		{"if (1 > 2) { 10 }", "if (1 > 2) { 10 }", nil},
 		{"if (1 > 2) { 10 } else { 20 }", "if (1 > 2) { 10 } else { 20 }", 20},
 		{"if (1 < 2) { 10 } else { 20 }", "if (1 < 2) { 10 } else { 20 }", 10},
+		{"elif taken", "if (1 > 2) { 10 } elif (2 > 1) { 20 } else { 30 }", 20},
+		{"second elif taken", "if (false) { 10 } elif (false) { 20 } elif (true) { 30 } else { 40 }", 30},
+		{"elif falls to else", "if (false) { 10 } elif (false) { 20 } else { 30 }", 30},
+		{"elif without else", "if (false) { 10 } elif (false) { 20 }", nil},
+		{"first truthy wins", "if (false) { 10 } elif (1) { 20 } elif (true) { 30 }", 20},
 	}
 
 	for _, test := range tests {
@@ -248,6 +437,110 @@ func TestErrorHandling(t *testing.T) {
 			"foobar",
 			"identifier not found: foobar",
 		},
+		{
+			"true && foobar",
+			"identifier not found: foobar",
+		},
+		{
+			"-true + 1.5",
+			"unknown operator: -BOOLEAN",
+		},
+		{
+			"1.5 + true",
+			"type mismatch: FLOAT + BOOLEAN",
+		},
+		{
+			`int("abc")`,
+			`cannot convert "abc" to INTEGER`,
+		},
+		{
+			`int("1__000")`,
+			`cannot convert "1__000" to INTEGER`,
+		},
+		{
+			`int("_1")`,
+			`cannot convert "_1" to INTEGER`,
+		},
+		{
+			`int("0x")`,
+			`cannot convert "0x" to INTEGER`,
+		},
+		{
+			`int("09a")`,
+			`cannot convert "09a" to INTEGER`,
+		},
+		{
+			"1 / 0",
+			"division by zero",
+		},
+		{
+			"def x = 10; x /= 0;",
+			"division by zero",
+		},
+		{
+			"1.5 / 0",
+			"division by zero",
+		},
+		{
+			"1 % 0",
+			"modulo by zero",
+		},
+		{
+			"0 ** -1",
+			"division by zero",
+		},
+		{
+			"1 << -1",
+			"negative shift count: -1",
+		},
+		{
+			"1 << 100000000000",
+			"integer overflow: result of << would exceed 1048576 bits",
+		},
+		{
+			"(1 << 1048576) << 1",
+			"integer overflow: result of << would exceed 1048576 bits",
+		},
+		{
+			"2 ** 2 ** 40",
+			"integer overflow: result of ** would exceed 1048576 bits",
+		},
+		{
+			"10 ** 1000000",
+			"integer overflow: result of ** would exceed 1048576 bits",
+		},
+		{
+			"1.5 & 1",
+			"unknown operator: FLOAT & INTEGER",
+		},
+		{
+			"~1.5",
+			"unknown operator: ~FLOAT",
+		},
+		{
+			`"a" % "b"`,
+			"unknown operator: STRING % STRING",
+		},
+		{
+			"def f = fun(a, b) { a + b }; f(1);",
+			"too few arguments. got=1, want=2",
+		},
+		{
+			"fun() { 1 }(1, 2)",
+			"too many arguments. got=2, want=0",
+		},
+		{
+			"def f = fun(n) { f(n + 1) }; f(0);",
+			"maximum call depth of 10000 exceeded",
+		},
+		{
+			"def f = fun(n) { 1 + (2 + (3 + (4 + (5 + (6 + (7 + (8 + f(n + 1)))))))) }; f(0);",
+			"maximum nesting depth of 100000 exceeded",
+		},
+		{
+			"def f = fun(n) { if (true) { while (true) { for (x in [1]) { if (x) { return [f(n + 1)][0]; } } } } }; f(0);",
+			"maximum nesting depth of 100000 exceeded",
+		},
 		{
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
@@ -276,6 +569,100 @@ func TestErrorHandling(t *testing.T) {
 	}
 }
 
+func TestErrorPositionsAndTraceback(t *testing.T) {
+	t.Parallel()
+	input := `def helper = fun(x) {
+	return x + missing;
+};
+def outer = fun() {
+	helper(1)
+};
+outer();`
+
+	evaluated := testEval(input)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T", evaluated)
+	}
+
+	if errObj.Position.Line != 2 || errObj.Position.Column != 13 {
+		t.Errorf("wrong error position. expected=2:13, got=%s", errObj.Position)
+	}
+
+	expectedFrames := []struct {
+		function string
+		line     int
+		column   int
+	}{
+		{"helper", 5, 2},
+		{"outer", 7, 1},
+	}
+
+	if len(errObj.Stack) != len(expectedFrames) {
+		t.Fatalf("wrong number of frames. expected=%d, got=%d", len(expectedFrames), len(errObj.Stack))
+	}
+
+	for i, expected := range expectedFrames {
+		frame := errObj.Stack[i]
+		if frame.Function != expected.function {
+			t.Errorf("frames[%d] wrong function. expected=%q, got=%q", i, expected.function, frame.Function)
+		}
+
+		if frame.Position.Line != expected.line || frame.Position.Column != expected.column {
+			t.Errorf("frames[%d] wrong position. expected=%d:%d, got=%s",
+				i, expected.line, expected.column, frame.Position)
+		}
+	}
+
+	expectedTraceback := `Error: identifier not found: missing
+    at 2:13
+    in helper called at 5:2
+    in outer called at 7:1`
+
+	if errObj.Traceback() != expectedTraceback {
+		t.Errorf("wrong traceback. expected=%q, got=%q", expectedTraceback, errObj.Traceback())
+	}
+}
+
+func TestTracebackIsBounded(t *testing.T) {
+	t.Parallel()
+	evaluated := testEval("def down = fun(n) { if (n == 0) { return 1 / 0; } down(n - 1) }; down(100);")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T", evaluated)
+	}
+
+	if len(errObj.Stack) != object.MaxTracebackFrames {
+		t.Errorf("wrong number of frames. expected=%d, got=%d", object.MaxTracebackFrames, len(errObj.Stack))
+	}
+
+	if errObj.Dropped != 101-object.MaxTracebackFrames {
+		t.Errorf("wrong number of dropped frames. expected=%d, got=%d", 101-object.MaxTracebackFrames, errObj.Dropped)
+	}
+}
+
+func TestSafeEvalRecoversPanics(t *testing.T) {
+	t.Parallel()
+	// an if expression without a consequence cannot come out of the
+	// parser, and makes Eval dereference a nil block
+	node := &ast.IfExpression{
+		Condition: &ast.Boolean{Value: true},
+	}
+
+	evaluated := SafeEval(node, object.NewEnvironment())
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T", evaluated)
+	}
+
+	if !strings.HasPrefix(errObj.Message, "internal error: ") {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
 func TestDefStatements(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -295,6 +682,240 @@ func TestDefStatements(t *testing.T) {
 	}
 }
 
+func TestAssignExpressions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"def a = 5; a = 10; a;", 10},
+		{"def a = 5; a = a * 2;", 10},
+		{"def a = 1; def b = 2; a = b = 7; a + b;", 14},
+		{"def a = 5; a += 3; a;", 8},
+		{"def a = 5; a -= 3; a;", 2},
+		{"def a = 5; a *= 3; a;", 15},
+		{"def a = 15; a /= 3; a;", 5},
+		{"def a = 1; def f = fun() { a = 42; }; f(); a;", 42},
+		{"def a = 1; def f = fun() { def a = 2; a = 3; }; f(); a;", 1},
+		{
+			`
+			def counter = fun() {
+				def count = 0;
+				return fun() { count += 1; };
+			};
+			def next = counter();
+			next();
+			next();
+			next();
+			`,
+			3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			testIntegerObject(t, testEval(test.input), test.expected)
+		})
+	}
+}
+
+func TestAssignExpressionErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"x = 1;", "cannot assign to undefined identifier: x"},
+		{"x += 1;", "cannot assign to undefined identifier: x"},
+		{"def f = fun() { y = 1; }; f();", "cannot assign to undefined identifier: y"},
+		{`def s = "a"; s -= "b";`, "unknown operator: STRING - STRING"},
+		{"def a = 1; a = b;", "identifier not found: b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			errObj, ok := testEval(test.input).(*object.Error)
+			if !ok {
+				t.Fatalf("no error object returned for %q", test.input)
+			}
+
+			if errObj.Message != test.expectedMessage {
+				t.Errorf("wrong error message. expected=%q, got=%q", test.expectedMessage, errObj.Message)
+			}
+		})
+	}
+}
+
+func TestWhileStatements(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected int64
+	}{
+		{"simple", "def i = 0; while (i < 10) { i += 1; } i;", 10},
+		{"false condition", "def i = 0; while (false) { i += 1; } i;", 0},
+		{"break", "def i = 0; while (true) { i += 1; if (i == 7) { break; } } i;", 7},
+		{
+			"continue",
+			"def i = 0; def sum = 0; while (i < 10) { i += 1; if (i <= 5) { continue; } sum += i; } sum;",
+			40,
+		},
+		{
+			"nested loops break inner only",
+			`
+			def i = 0;
+			def count = 0;
+			while (i < 3) {
+				i += 1;
+				def j = 0;
+				while (true) {
+					j += 1;
+					if (j > 4) { break; }
+					count += 1;
+				}
+			}
+			count;
+			`,
+			12,
+		},
+		{
+			"return from loop inside function",
+			"def f = fun() { def i = 0; while (true) { i += 1; if (i == 3) { return i * 10; } } }; f();",
+			30,
+		},
+		{"many iterations", "def i = 0; while (i < 100000) { i += 1; } i;", 100000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			testIntegerObject(t, testEval(test.input), test.expected)
+		})
+	}
+}
+
+func TestWhileStatementErrors(t *testing.T) {
+	t.Parallel()
+	evaluated := testEval("def i = 0; while (i < 3) { i += 1; i + true; }")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T", evaluated)
+	}
+
+	if errObj.Message != "type mismatch: INTEGER + BOOLEAN" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+}
+
+func TestForStatements(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"array", "def sum = 0; for (x in [1, 2, 3, 4]) { sum += x; } sum;", 10},
+		{"array with index", "def sum = 0; for (i, x in [10, 20, 30]) { sum += i * x; } sum;", 80},
+		{"empty array", "def n = 0; for (x in []) { n += 1; } n;", 0},
+		{"string", `def out = ""; for (ch in "abc") { out = ch + out; } out;`, "cba"},
+		{"unicode string", `def out = ""; for (i, ch in "سلام😀") { out = ch + out; } out;`, "😀مالس"},
+		{"unicode string index", `def last = 0; for (i, ch in "سلام😀") { last = i; } last;`, 4},
+		{"hash keys", `def out = ""; for (k in {"b": 2, "a": 1, "c": 3}) { out += k; } out;`, "abc"},
+		{
+			"hash pairs",
+			`def out = ""; for (k, v in {"b": "2", "a": "1"}) { out += k + v; } out;`,
+			"a1b2",
+		},
+		{"integer hash keys in order", "def out = []; for (k in {3: 0, -1: 0, 2: 0}) { out = push_back(out, k); } out[0];", -1},
+		{"range", "def sum = 0; for (i in range(5)) { sum += i; } sum;", 10},
+		{"range with start and step", "def sum = 0; for (i in range(10, 0, -3)) { sum += i; } sum;", 22},
+		{"break", "def n = 0; for (x in range(100)) { if (x == 5) { break; } n += 1; } n;", 5},
+		{"continue", "def n = 0; for (x in range(10)) { if (x < 8) { continue; } n += x; } n;", 17},
+		{"loop variable does not leak", "def x = 42; for (x in [1, 2]) { x; } x;", 42},
+		{
+			"closures capture each element",
+			"def fs = []; for (x in [1, 2, 3]) { fs = push_back(fs, fun() { x }); } fs[0]() + fs[2]();",
+			4,
+		},
+		{"not iterable", "for (x in 5) { x; }", "cannot iterate over INTEGER"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+
+			case string:
+				switch evaluated := evaluated.(type) {
+				case *object.String:
+					if evaluated.Value != expected {
+						t.Errorf("String has wrong value. expected=%q, got=%q", expected, evaluated.Value)
+					}
+				case *object.Error:
+					if evaluated.Message != expected {
+						t.Errorf("wrong error message. expected=%q, got=%q", expected, evaluated.Message)
+					}
+				default:
+					t.Errorf("object is not String or Error. got=%T (%+v)", evaluated, evaluated)
+				}
+			}
+		})
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true and false", false},
+		{"false or true", true},
+		{"not true", false},
+		{"not false", true},
+		{"not 1 == 2", true},
+		{"!1 == 2", false},
+		{"not 1 < 2 and true", false},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
+		{"1 && 2", 2},
+		{"0 || 5", 0},
+		{"false || 7", 7},
+		{`if (false) { 1 } || "x"`, "x"},
+		{"false && undefined_name", false},
+		{"true || undefined_name", true},
+		{"def calls = 0; def f = fun() { calls += 1; true }; false && f(); true || f(); calls;", 0},
+		{"def calls = 0; def f = fun() { calls += 1; true }; true && f(); false || f(); calls;", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			switch expected := test.expected.(type) {
+			case bool:
+				testBoolObject(t, evaluated, expected)
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case string:
+				str, ok := evaluated.(*object.String)
+				if !ok || str.Value != expected {
+					t.Errorf("expected String %q. got=%T (%+v)", expected, evaluated, evaluated)
+				}
+			}
+		})
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	t.Parallel()
 	input := "fun(x) {x + 2; };"
@@ -371,6 +992,98 @@ func TestStringLiteral(t *testing.T) {
 	}
 }
 
+func TestStringEscapesAndRawStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"line\none"`, "line\none"},
+		{`"{\"key\": \"value\"}"`, `{"key": "value"}`},
+		{"`C:\\path\\file`", `C:\path\file`},
+		{"\"\"\"[server]\nport = 80\n\"\"\"", "[server]\nport = 80\n"},
+		{`"tab\t" + "end"`, "tab\tend"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("%s: object is not String. got=%T (%+v)", test.input, evaluated, evaluated)
+		}
+
+		if str.Value != test.expected {
+			t.Errorf("%s: String has wrong value. expected=%q, got=%q", test.input, test.expected, str.Value)
+		}
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`def name = "Ali"; "Hello ${name}!"`, "Hello Ali!"},
+		{`def age = 20; "${name} is ${age + 1}"`, "identifier not found: name"},
+		{`"${1.5} ${true} ${[1, "a"]} ${if (false) { 1 }}"`, "1.5 true [1, a] null"},
+		{`def f = fun(x) { x * 2 }; "f(2) = ${f(2)}"`, "f(2) = 4"},
+		{`def xs = [1, 2]; "${len(xs)} items: ${xs[0]}, ${xs[1]}"`, "2 items: 1, 2"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"${1 / 0}"`, "division by zero"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+
+		if err, ok := evaluated.(*object.Error); ok {
+			if err.Message != test.expected {
+				t.Errorf("%s: wrong error message. expected=%q, got=%q", test.input, test.expected, err.Message)
+			}
+			continue
+		}
+
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("%s: object is not String. got=%T (%+v)", test.input, evaluated, evaluated)
+		}
+		if str.Value != test.expected {
+			t.Errorf("%s: String has wrong value. expected=%q, got=%q", test.input, test.expected, str.Value)
+		}
+	}
+}
+
+func TestStringIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"سلام"[1]`, "ل"},
+		{`"a😀b"[1]`, "😀"},
+		{`"a😀b"[2]`, "b"},
+		{`"abc"[3]`, nil},
+		{`"abc"[-1]`, nil},
+		{`def s = "دنیا"; s[len(s) - 1]`, "ا"},
+	}
+
+	for _, test := range tests {
+		evaluated := testEval(test.input)
+
+		if test.expected == nil {
+			testNullObject(t, evaluated)
+			continue
+		}
+
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Fatalf("%s: object is not String. got=%T (%+v)", test.input, evaluated, evaluated)
+		}
+		if str.Value != test.expected {
+			t.Errorf("%s: String has wrong value. expected=%q, got=%q", test.input, test.expected, str.Value)
+		}
+	}
+}
+
 func TestStringConcatenation(t *testing.T) {
 	t.Parallel()
 
@@ -395,8 +1108,25 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("سلام")`, 4},
+		{`len("😀!")`, 2},
+		{`len(bytes("سلام"))`, 8},
+		{`bytes("a")[0]`, 97},
+		{`bytes(1)`, "argument to 'bytes' must be STRING, got INTEGER"},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
+		{`len(range(4))`, 4},
+		{`len(range(2, 9, 3))`, 3},
+		{`range(1, 2, 0)`, "step of 'range' must not be zero"},
+		{`range("a")`, "arguments to 'range' must be INTEGER. got STRING"},
+		{`len(range(10, 0, -3))`, 4},
+		{`len(range(5, 5))`, 0},
+		{`len(range(0, 5, -1))`, 0},
+		{`range(9223372036854775800, 9223372036854775807, 3)[2]`, 9223372036854775806},
+		{`len(range(-9223372036854775807, 9223372036854775807, 4611686018427387904))`, 4},
+		{`range(1000000000)`, "'range' would produce 1000000000 elements, more than the limit of 10000000"},
+		{`for (i in range(1000000000)) { i }`, "'range' would produce 1000000000 elements, more than the limit of 10000000"},
+		{`range(-9223372036854775807, 9223372036854775807)`, "'range' would produce 18446744073709551614 elements, more than the limit of 10000000"},
 	}
 
 	for _, test := range tests {
@@ -581,6 +1311,30 @@ func TestHashIndexExpressions(t *testing.T) {
 			`{false: 5}[false]`,
 			5,
 		},
+		{
+			`{1.0: 5}[1]`,
+			5,
+		},
+		{
+			`{1: 5}[1.0]`,
+			5,
+		},
+		{
+			`{-2: 5}[-2.0]`,
+			5,
+		},
+		{
+			`{9223372036854775808: 5}[9223372036854775808.0]`,
+			5,
+		},
+		{
+			`{1: 5}[1.5]`,
+			nil,
+		},
+		{
+			`{1: 4, 1.0: 5}[1]`,
+			5,
+		},
 	}
 
 	for _, test := range tests {
@@ -595,3 +1349,107 @@ func TestHashIndexExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestNumberAndCharLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o755", 493},
+		{"0b1010 + 1", 11},
+		{"1_000_000 / 1_000", 1000},
+		{"0x10 * 0b10", 32},
+		{"'a'", 97},
+		{"'a' + 1", 98},
+		{`'\n'`, 10},
+		{`'\u{1F600}'`, 128512},
+		{"'z' - 'a'", 25},
+	}
+
+	for _, test := range tests {
+		testIntegerObject(t, testEval(test.input), test.expected)
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 / 2", 3},
+		{"-7 / 2", -4},
+		{"7 / -2", -4},
+		{"-7 / -2", 3},
+		{"-6 / 2", -3},
+		{"7 % 3", 1},
+		{"-7 % 3", 2},
+		{"7 % -3", -2},
+		{"-7 % -3", -1},
+		{"def a = -7; def b = 3; (a / b) * b + a % b", -7},
+		{"7.5 % 2", 1.5},
+		{"-7.5 % 2", 0.5},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"2 ** 0", 1},
+		{"2 ** -1", 0.5},
+		{"2.0 ** 0.5 * 2.0 ** 0.5", 2.0000000000000004},
+		{"0b1100 & 0b1010", 8},
+		{"0b1100 | 0b1010", 14},
+		{"0b1100 ^ 0b1010", 6},
+		{"~0", -1},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"1024 >> 3", 128},
+		{"-16 >> 2", -4},
+		{"-1 >> 100", -1},
+		{"0o755 & 0o070", 0o050},
+		{"def flags = 0b101; flags & 0b100 == 0b100", true},
+		{"def flags = 0b101; flags & ~0b001", 4},
+		{"(1 << 70) >> 69", 2},
+		{"((1 << 64) | 1) & 0xFF", 1},
+		{"~(1 << 64) + (1 << 64)", -1},
+		{"1 ** 100000000000000000000", 1},
+		{"(-1) ** 100000000001", -1},
+		{"0 ** 100000000000", 0},
+		{"0 << 100000000000", 0},
+		{"5 >> 100000000000000000000", 0},
+		{"-5 >> 100000000000000000000", -1},
+		{"(1 << 100) >> 1000", 0},
+		{"((1 << 1048575) >> 1048574)", 2},
+		{"-(2 ** 70) / 3 * 3 + -(2 ** 70) % 3 == -(2 ** 70)", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			evaluated := testEval(test.input)
+
+			switch expected := test.expected.(type) {
+			case int:
+				testIntegerObject(t, evaluated, int64(expected))
+			case float64:
+				testFloatObject(t, evaluated, expected)
+			case bool:
+				testBoolObject(t, evaluated, expected)
+			}
+		})
+	}
+
+	for _, test := range []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"1 << 64", "18446744073709551616"},
+		{"9223372036854775807 << 1", "18446744073709551614"},
+		{"-(2 ** 70) / 3", "-393530540239137101142"},
+	} {
+		evaluated := testEval(test.input)
+		if evaluated.Inspect() != test.expected {
+			t.Errorf("%s: wrong value. expected=%s, got=%s", test.input, test.expected, evaluated.Inspect())
+		}
+	}
+}
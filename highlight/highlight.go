@@ -0,0 +1,204 @@
+// Package highlight renders source code and values with the colors of
+// the color package.
+package highlight
+
+import (
+	"Ahmadi/color"
+	"Ahmadi/lexer"
+	"Ahmadi/object"
+	"Ahmadi/token"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Code colors the tokens of source, keeping the text between them as it
+// is. Keywords, strings, numbers, operators, identifiers and comments
+// each get their own color.
+func Code(source string) string {
+	var out strings.Builder
+	lex := lexer.New(source)
+	lex.KeepComments()
+	position := 0
+
+	for {
+		tok := lex.NextToken()
+		if tok.Offset < position || tok.EndOffset > len(source) {
+			break
+		}
+
+		for _, comment := range tok.Comments {
+			out.WriteString(source[position:comment.Start.Offset])
+			out.WriteString(color.Gray(comment.Text))
+			position = comment.End.Offset
+		}
+
+		out.WriteString(source[position:tok.Offset])
+		out.WriteString(paint(tok, source[tok.Offset:tok.EndOffset]))
+		position = tok.EndOffset
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+
+	out.WriteString(source[position:])
+	return out.String()
+}
+
+func paint(tok token.Token, text string) string {
+	switch tok.Type {
+	case token.STRING, token.STRING_START, token.STRING_MIDDLE, token.STRING_END:
+		return color.Green(text)
+	case token.INT, token.FLOAT, token.CHAR, token.TRUE, token.FALSE:
+		return color.Cyan(text)
+	case token.ID:
+		return color.Blue(text)
+	case token.ILLEGAL:
+		return color.Red(text)
+	case token.EOF, token.LPARENTHESES, token.RPARENTHESES, token.LBRACE, token.RBRACE,
+		token.LBRACKET, token.RBRACKET, token.COMMA, token.SEMICOLON, token.COLON:
+		return text
+	}
+
+	if token.LookupIdentifier(text) == tok.Type {
+		return color.Purple(text)
+	}
+	return color.Yellow(text)
+}
+
+// Value renders obj the way it is shown in the REPL: strings are quoted,
+// and arrays and hashes holding other non-empty arrays or hashes are
+// spread over indented lines.
+func Value(obj object.Object) string {
+	var out strings.Builder
+	writeValue(&out, obj, "")
+	return out.String()
+}
+
+func writeValue(out *strings.Builder, obj object.Object, indent string) {
+	switch obj := obj.(type) {
+	case *object.String:
+		out.WriteString(color.Green(Quote(obj.Value)))
+
+	case *object.Integer, *object.Float, *object.BigInt, *object.Boolean:
+		out.WriteString(color.Cyan(obj.Inspect()))
+
+	case *object.Null:
+		out.WriteString(color.Gray(obj.Inspect()))
+
+	case *object.Function:
+		out.WriteString(Code(obj.Inspect()))
+
+	case *object.Builtin:
+		out.WriteString(color.Gray(obj.Inspect()))
+
+	case *object.Array:
+		nested := false
+		for _, element := range obj.Elements {
+			nested = nested || isNonEmptyContainer(element)
+		}
+		if !nested {
+			out.WriteString("[")
+			for i, element := range obj.Elements {
+				if i > 0 {
+					out.WriteString(", ")
+				}
+				writeValue(out, element, indent)
+			}
+			out.WriteString("]")
+			return
+		}
+
+		out.WriteString("[\n")
+		for _, element := range obj.Elements {
+			out.WriteString(indent + "  ")
+			writeValue(out, element, indent+"  ")
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "]")
+
+	case *object.Hash:
+		pairs := obj.SortedPairs()
+		nested := false
+		for _, pair := range pairs {
+			nested = nested || isNonEmptyContainer(pair.Value)
+		}
+		if !nested {
+			out.WriteString("{")
+			for i, pair := range pairs {
+				if i > 0 {
+					out.WriteString(", ")
+				}
+				writeKey(out, pair.Key)
+				out.WriteString(": ")
+				writeValue(out, pair.Value, indent)
+			}
+			out.WriteString("}")
+			return
+		}
+
+		out.WriteString("{\n")
+		for _, pair := range pairs {
+			out.WriteString(indent + "  ")
+			writeKey(out, pair.Key)
+			out.WriteString(": ")
+			writeValue(out, pair.Value, indent+"  ")
+			out.WriteString(",\n")
+		}
+		out.WriteString(indent + "}")
+
+	default:
+		out.WriteString(obj.Inspect())
+	}
+}
+
+func writeKey(out *strings.Builder, key object.Object) {
+	text := key.Inspect()
+	if key.Type() == object.STRING_OBJ {
+		text = Quote(text)
+	}
+	out.WriteString(color.Purple(text))
+}
+
+func isNonEmptyContainer(obj object.Object) bool {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return len(obj.Elements) > 0
+	case *object.Hash:
+		return len(obj.Pairs) > 0
+	}
+	return false
+}
+
+// Quote writes str as an APL string literal, so that it reads back as
+// the same string. Only the escapes the lexer understands are used:
+// \n \t \r \0 \\ \" and \${, and \u{...} for characters that cannot
+// be printed. Printable characters, including the zero-width joiners
+// of Persian and Arabic text, are kept as they are.
+func Quote(str string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i, ch := range str {
+		switch {
+		case ch == '"' || ch == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(ch)
+		case ch == '$' && strings.HasPrefix(str[i+1:], "{"):
+			out.WriteString(`\$`)
+		case ch == '\n':
+			out.WriteString(`\n`)
+		case ch == '\t':
+			out.WriteString(`\t`)
+		case ch == '\r':
+			out.WriteString(`\r`)
+		case ch == 0:
+			out.WriteString(`\0`)
+		case unicode.IsPrint(ch) || ch == '\u200c' || ch == '\u200d':
+			out.WriteRune(ch)
+		default:
+			fmt.Fprintf(&out, `\u{%x}`, ch)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
@@ -0,0 +1,124 @@
+package highlight
+
+import (
+	"Ahmadi/color"
+	"Ahmadi/evaluator"
+	"Ahmadi/lexer"
+	"Ahmadi/object"
+	"Ahmadi/parser"
+	"Ahmadi/token"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	color.Active = true
+	defer func() { color.Active = false }()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"def x = 5;", color.Purple("def") + " " + color.Blue("x") + " " + color.Yellow("=") + " " + color.Cyan("5") + ";"},
+		{`len("ab")`, color.Blue("len") + "(" + color.Green(`"ab"`) + ")"},
+		{"a and not b", color.Blue("a") + " " + color.Purple("and") + " " + color.Purple("not") + " " + color.Blue("b")},
+		{"a && b", color.Blue("a") + " " + color.Yellow("&&") + " " + color.Blue("b")},
+		{"[true, 1.5]  ", "[" + color.Cyan("true") + ", " + color.Cyan("1.5") + "]  "},
+		{`"a ${b} c"`, color.Green(`"a ${`) + color.Blue("b") + color.Green(`} c"`)},
+		{"1 @ 2", color.Cyan("1") + " " + color.Red("@") + " " + color.Cyan("2")},
+		{"\t{}\n", "\t{}\n"},
+	}
+
+	for _, test := range tests {
+		got := Code(test.input)
+		if got != test.expected {
+			t.Errorf("Code(%q) wrong.\nexpected=%q\ngot=     %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestCodeWithoutColors(t *testing.T) {
+	color.Active = false
+	inputs := []string{"def f = fun(x) { x * 2 };", `"unterminated`, "a >= b || c", ""}
+
+	for _, input := range inputs {
+		if got := Code(input); got != input {
+			t.Errorf("Code(%q) changed the text. got=%q", input, got)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	color.Active = false
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`5`, "5"},
+		{`"hi"`, `"hi"`},
+		{`"say \"hi\"\n"`, `"say \"hi\"\n"`},
+		{`"\u{7}"`, `"\u{7}"`},
+		{`"می\u{200c}خواهم"`, "\"می\u200cخواهم\""},
+		{`{"\${k}": 1}`, `{"\${k}": 1}`},
+		{`if (false) { 1 }`, "null"},
+		{`[]`, "[]"},
+		{`[1, "a", true]`, `[1, "a", true]`},
+		{`{"b": 2, "a": [], 1: "x"}`, `{1: "x", "a": [], "b": 2}`},
+		{`[[1, 2], [3]]`, "[\n  [1, 2],\n  [3],\n]"},
+		{`{"name": "ali", "tags": ["a", "b"], "meta": {"x": {"y": 1}}}`,
+			"{\n  \"meta\": {\n    \"x\": {\"y\": 1},\n  },\n  \"name\": \"ali\",\n  \"tags\": [\"a\", \"b\"],\n}"},
+	}
+
+	for _, test := range tests {
+		p := parser.New(lexer.New(test.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("parser errors for %q: %v", test.input, p.Errors())
+		}
+		evaluated := evaluator.Eval(program, object.NewEnvironment())
+
+		if got := Value(evaluated); got != test.expected {
+			t.Errorf("Value(%s) wrong.\nexpected=%q\ngot=     %q", test.input, test.expected, got)
+		}
+	}
+}
+
+func TestCodeComments(t *testing.T) {
+	color.Active = true
+	defer func() { color.Active = false }()
+
+	input := "x /* a */ // b\n"
+	expected := color.Blue("x") + " " + color.Gray("/* a */") + " " + color.Gray("// b") + "\n"
+
+	if got := Code(input); got != expected {
+		t.Errorf("Code(%q) wrong.\nexpected=%q\ngot=     %q", input, expected, got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"hi", `"hi"`},
+		{"say \"hi\"\n", `"say \"hi\"\n"`},
+		{`C:\Users`, `"C:\\Users"`},
+		{"a\tb\r\x00", `"a\tb\r\0"`},
+		{"\a", `"\u{7}"`},
+		{"\x01\u2028", `"\u{1}\u{2028}"`},
+		{"${x} $y", `"\${x} $y"`},
+		{"می\u200cخواهم", "\"می\u200cخواهم\""},
+		{"😀", `"😀"`},
+	}
+
+	for _, test := range tests {
+		quoted := Quote(test.input)
+		if quoted != test.expected {
+			t.Errorf("Quote(%q) wrong. expected=%s, got=%s", test.input, test.expected, quoted)
+		}
+
+		tok := lexer.New(quoted).NextToken()
+		if tok.Type != token.STRING || tok.Literal != test.input {
+			t.Errorf("%s does not read back. got=%s %q (%s)", quoted, tok.Type, tok.Literal, tok.Error)
+		}
+	}
+}
@@ -2,36 +2,91 @@ package lexer
 
 import (
 	"Ahmadi/token"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
-	input        string
-	position     int
-	readPosition int
-	ch           byte
+	input          string
+	fileName       string
+	position       int
+	readPosition   int
+	ch             rune
+	line           int
+	column         int
+	keepComments   bool
+	interpolations []interpolation // open `${` of enclosing strings, innermost last
 }
 
 func New(input string) *Lexer {
+	return NewFile("", input)
+}
+
+// NewFile returns a lexer whose tokens carry name as their FileName.
+func NewFile(name string, input string) *Lexer {
 	lexer := &Lexer{
-		input: input,
+		input:    input,
+		fileName: name,
+		line:     1,
 	}
 	lexer.readChar()
 	return lexer
 }
 
+// readChar advances to the next character, decoding the input as UTF-8.
+// Columns count characters, while offsets count bytes.
 func (l *Lexer) readChar() {
+	if l.readPosition > len(l.input) {
+		return
+	}
+
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+
+	size := 1
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch, size = utf8.DecodeRuneInString(l.input[l.readPosition:])
 	}
 	l.position = l.readPosition
-	l.readPosition++
+	l.readPosition += size
+	l.column++
+}
+
+// KeepComments makes the lexer attach the comments before each token to
+// its Comments field, instead of dropping them.
+func (l *Lexer) KeepComments() {
+	l.keepComments = true
 }
 
+// NextToken returns the next token in the input, annotated with the
+// position where it starts and the position just past its end.
 func (l *Lexer) NextToken() token.Token {
+	comments := l.skipTrivia()
+
+	line, column, offset := l.line, l.column, l.position
+	tok := l.scanToken()
+	tok.Comments = comments
+
+	tok.FileName = l.fileName
+	tok.LineNumber = line
+	tok.Column = column
+	tok.Offset = offset
+	tok.EndLine = l.line
+	tok.EndColumn = l.column
+	tok.EndOffset = l.position
+	return tok
+}
+
+func (l *Lexer) scanToken() token.Token {
 	var tok token.Token
-	l.skipWhitespace()
 
 	switch l.ch {
 	case '*':
@@ -43,12 +98,38 @@ func (l *Lexer) NextToken() token.Token {
 				Type:    token.SHORT_MULTIPLY,
 				Literal: literal,
 			}
+		} else if l.peekChar() == '*' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.POWER,
+				Literal: literal,
+			}
 		} else {
 			tok = newToken(token.ASTERISK, l.ch)
 		}
 
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
+
+	case '^':
+		tok = newToken(token.BITWISE_XOR, l.ch)
+
+	case '~':
+		tok = newToken(token.BITWISE_NOT, l.ch)
+
 	case '/':
-		if l.peekChar() == '=' {
+		if l.peekChar() == '*' {
+			// skipTrivia leaves only block comments that are never closed
+			tok.Type = token.ILLEGAL
+			tok.Literal = l.input[l.position:]
+			tok.Error = UnterminatedBlockComment
+			for l.ch != 0 {
+				l.readChar()
+			}
+			return tok
+		} else if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -87,9 +168,36 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	case '>':
-		tok = newToken(token.GREATER, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.GREATEREQUAL,
+				Literal: literal,
+			}
+		} else if l.peekChar() == '>' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.SHIFT_RIGHT,
+				Literal: literal,
+			}
+		} else {
+			tok = newToken(token.GREATER, l.ch)
+		}
+
 	case '<':
-		if l.peekChar() == '>' {
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.SMALLEREQUAL,
+				Literal: literal,
+			}
+		} else if l.peekChar() == '>' {
 			ch := l.ch
 			l.readChar()
 			literal := string(ch) + string(l.ch)
@@ -97,6 +205,14 @@ func (l *Lexer) NextToken() token.Token {
 				Type:    token.NOT_EQUALITY_SIGNS,
 				Literal: literal,
 			}
+		} else if l.peekChar() == '<' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.SHIFT_LEFT,
+				Literal: literal,
+			}
 		} else {
 			tok = newToken(token.SMALLER, l.ch)
 		}
@@ -114,6 +230,32 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 
+	case '&':
+		if l.peekChar() == '&' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.AND,
+				Literal: literal,
+			}
+		} else {
+			tok = newToken(token.BITWISE_AND, l.ch)
+		}
+
+	case '|':
+		if l.peekChar() == '|' {
+			ch := l.ch
+			l.readChar()
+			literal := string(ch) + string(l.ch)
+			tok = token.Token{
+				Type:    token.OR,
+				Literal: literal,
+			}
+		} else {
+			tok = newToken(token.BITWISE_OR, l.ch)
+		}
+
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case '(':
@@ -121,8 +263,19 @@ func (l *Lexer) NextToken() token.Token {
 	case ')':
 		tok = newToken(token.RPARENTHESES, l.ch)
 	case '}':
+		if n := len(l.interpolations); n > 0 {
+			if l.interpolations[n-1].depth == 0 {
+				kind := l.interpolations[n-1].kind
+				l.interpolations = l.interpolations[:n-1]
+				return l.readQuoted(kind, true)
+			}
+			l.interpolations[n-1].depth--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '{':
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1].depth++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
@@ -142,8 +295,16 @@ func (l *Lexer) NextToken() token.Token {
 		}
 
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		if l.peekChar() == '"' && l.peekCharAt(2) == '"' {
+			return l.readMultiLineString()
+		}
+		return l.readString()
+
+	case '`':
+		return l.readRawString()
+
+	case '\'':
+		return l.readCharLiteral()
 
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
@@ -160,10 +321,14 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Literal = l.readIndentifier()
 			tok.Type = token.LookupIdentifier(tok.Literal)
 			return tok
-		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
-			return tok
+		} else if isDigit(l.ch) || (l.ch == '.' && isDigit(l.peekChar())) {
+			return l.readNumber()
+		} else if l.ch == utf8.RuneError && l.readPosition-l.position == 1 {
+			tok = token.Token{
+				Type:    token.ILLEGAL,
+				Literal: l.input[l.position:l.readPosition],
+				Error:   "invalid UTF-8 encoding",
+			}
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -172,40 +337,384 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func (l *Lexer) readString() string {
-	position := l.position + 1
+// stringKind describes one of the ways to write a string literal.
+type stringKind struct {
+	delimiter    string
+	multiLine    bool // may span lines
+	escapes      bool // backslash starts an escape sequence
+	interpolates bool // `${expr}` embeds the value of expr
+	unterminated string
+}
 
-	for {
+// Errors of ILLEGAL tokens that run to the end of the input because a
+// construct allowed to span lines was never closed. More input may
+// still complete them.
+const (
+	UnterminatedBlockComment    = "unterminated block comment"
+	UnterminatedMultiLineString = "unterminated multi-line string"
+	UnterminatedRawString       = "unterminated raw string"
+)
+
+var (
+	quotedString    = &stringKind{`"`, false, true, true, "unterminated string"}
+	multiLineString = &stringKind{`"""`, true, true, true, UnterminatedMultiLineString}
+	rawString       = &stringKind{"`", true, false, false, UnterminatedRawString}
+)
+
+// interpolation is a `${` of a string literal that is not closed yet.
+// depth counts the braces opened inside the embedded expression.
+type interpolation struct {
+	kind  *stringKind
+	depth int
+}
+
+// readString reads a double-quoted string, which ends on the line it
+// starts on. Backslash escapes are replaced by the characters they stand
+// for.
+func (l *Lexer) readString() token.Token {
+	return l.readQuoted(quotedString, false)
+}
+
+// readMultiLineString reads a string between triple quotes. It may span
+// lines, and escapes and interpolation work as in double-quoted strings.
+func (l *Lexer) readMultiLineString() token.Token {
+	return l.readQuoted(multiLineString, false)
+}
+
+// readRawString reads a string between backticks. Its content is taken
+// as is: it may span lines and backslashes have no special meaning.
+func (l *Lexer) readRawString() token.Token {
+	return l.readQuoted(rawString, false)
+}
+
+// readQuoted reads a string literal from its opening delimiter, or from
+// the `}` ending an interpolation when resumed, up to the closing
+// delimiter or the next `${`. A string with interpolations is lexed as
+// STRING_START, the tokens of the first expression, STRING_MIDDLE, ...,
+// STRING_END, each carrying the text of its segment.
+//
+// A literal that is not closed, or holds an invalid escape, becomes an
+// ILLEGAL token spanning its source text, with Error telling what is
+// wrong.
+func (l *Lexer) readQuoted(kind *stringKind, resumed bool) token.Token {
+	start := l.position
+	if resumed {
 		l.readChar()
-		if l.ch == '"' || l.ch == 0 {
+	} else {
+		for range kind.delimiter {
+			l.readChar()
+		}
+	}
+
+	var value strings.Builder
+	problem := ""
+	var tokenType token.TokenType
+
+	for {
+		if l.ch == 0 || (l.ch == '\n' && !kind.multiLine) {
+			return token.Token{Type: token.ILLEGAL, Literal: l.input[start:l.position], Error: kind.unterminated}
+		}
+
+		if strings.HasPrefix(l.input[l.position:], kind.delimiter) {
+			for range kind.delimiter {
+				l.readChar()
+			}
+			tokenType = token.STRING
+			if resumed {
+				tokenType = token.STRING_END
+			}
+			break
+		}
+
+		if kind.interpolates && l.ch == '$' && l.peekChar() == '{' {
+			l.readChar()
+			l.readChar()
+			l.interpolations = append(l.interpolations, interpolation{kind: kind})
+			tokenType = token.STRING_START
+			if resumed {
+				tokenType = token.STRING_MIDDLE
+			}
 			break
 		}
+
+		if l.ch == '\\' && kind.escapes {
+			if err := l.readEscape(&value); err != "" && problem == "" {
+				problem = err
+			}
+			continue
+		}
+
+		// copied as bytes, so invalid UTF-8 is kept as it is
+		value.WriteString(l.input[l.position:l.readPosition])
+		l.readChar()
 	}
-	return l.input[position:l.position]
+
+	if problem != "" {
+		return token.Token{Type: token.ILLEGAL, Literal: l.input[start:l.position], Error: problem}
+	}
+	return token.Token{Type: tokenType, Literal: value.String()}
+}
+
+// escapes maps the character after a backslash to the one it stands for.
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'\\': '\\',
+	'"':  '"',
+	'\'': '\'',
+	'$':  '$',
+}
+
+// readEscape reads the escape sequence starting at the current backslash
+// and writes the character it stands for to value. `\u{...}` holds the
+// hexadecimal code point of a Unicode character. It returns a message
+// when the sequence is invalid.
+func (l *Lexer) readEscape(value *strings.Builder) string {
+	start := l.position
+	l.readChar()
+
+	if ch, ok := escapes[l.ch]; ok {
+		value.WriteRune(ch)
+		l.readChar()
+		return ""
+	}
+
+	if l.ch != 'u' {
+		if l.ch == 0 || l.ch == '\n' {
+			return "invalid escape sequence at end of line"
+		}
+		l.readChar()
+		return fmt.Sprintf("invalid escape sequence %q", l.input[start:l.position])
+	}
+
+	l.readChar()
+	if l.ch != '{' {
+		return fmt.Sprintf("invalid escape sequence %q, expected \\u{hex}", l.input[start:l.position])
+	}
+	l.readChar()
+
+	digits := l.position
+	for isHexDigit(l.ch) {
+		l.readChar()
+	}
+	hex := l.input[digits:l.position]
+	if l.ch != '}' {
+		return fmt.Sprintf("invalid escape sequence %q, expected \\u{hex}", l.input[start:l.position])
+	}
+	l.readChar()
+
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) > 6 || !utf8.ValidRune(rune(code)) {
+		return fmt.Sprintf("invalid Unicode code point %q", l.input[start:l.position])
+	}
+	value.WriteRune(rune(code))
+	return ""
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || ('a' <= ch && ch <= 'f') || ('A' <= ch && ch <= 'F')
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{
 		Type:    tokenType,
 		Literal: string(ch),
 	}
 }
 
-func isLetter(ch byte) bool {
-	return ('a' <= ch && ch <= 'z') || ('A' <= ch && 'Z' >= ch) || (ch == '_')
+// isLetter reports whether ch can start an identifier: a Unicode letter
+// or an underscore.
+func isLetter(ch rune) bool {
+	return unicode.IsLetter(ch) || ch == '_'
 }
 
-func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) {
+// isIdentifierChar reports whether ch can continue an identifier. Besides
+// letters, identifiers hold digits, combining marks and the zero-width
+// joiners used in Persian words.
+func isIdentifierChar(ch rune) bool {
+	return isLetter(ch) || unicode.IsDigit(ch) || unicode.In(ch, unicode.Mn, unicode.Mc) || ch == '\u200c' || ch == '\u200d'
+}
+
+// readNumber reads an integer or a float literal. Integers are decimal,
+// or hexadecimal, octal and binary with a `0x`, `0o` or `0b` prefix.
+// Floats have a fractional part (`3.14`, `.5`), an exponent (`1e-9`) or
+// both. Underscores may separate digits, as in `1_000_000`. A literal
+// running into letters, like `12abc`, is ILLEGAL.
+func (l *Lexer) readNumber() token.Token {
+	start := l.position
+	if l.ch == '0' && l.peekChar() != 0 && strings.ContainsRune("xXoObB", l.peekChar()) {
+		return l.readPrefixedNumber()
+	}
+
+	var tokenType token.TokenType = token.INT
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
 		l.readChar()
+		l.readDigits()
 	}
-	return l.input[position:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if next == '+' || next == '-' {
+			next = l.peekCharAt(2)
+		}
+
+		if isDigit(next) {
+			tokenType = token.FLOAT
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+
+	if isIdentifierChar(l.ch) {
+		for isIdentifierChar(l.ch) {
+			l.readChar()
+		}
+		return l.illegal(start, fmt.Sprintf("malformed number literal %q", l.input[start:l.position]))
+	}
+
+	literal := l.input[start:l.position]
+	if !separatesDigits(literal, isDigit) {
+		return l.illegal(start, fmt.Sprintf("'_' must separate successive digits in %q", literal))
+	}
+	return token.Token{Type: tokenType, Literal: literal}
+}
+
+// numberBases describes the integer literals written with a prefix.
+var numberBases = map[rune]struct {
+	name  string
+	digit func(rune) bool
+}{
+	'x': {"hexadecimal", isHexDigit},
+	'o': {"octal", func(ch rune) bool { return '0' <= ch && ch <= '7' }},
+	'b': {"binary", func(ch rune) bool { return ch == '0' || ch == '1' }},
+}
+
+// readPrefixedNumber reads a hexadecimal, octal or binary integer.
+func (l *Lexer) readPrefixedNumber() token.Token {
+	start := l.position
+	l.readChar()
+	base := numberBases[unicode.ToLower(l.ch)]
+	l.readChar()
+
+	for isIdentifierChar(l.ch) {
+		l.readChar()
+	}
+	literal := l.input[start:l.position]
+	digits := literal[2:]
+
+	if strings.Trim(digits, "_") == "" {
+		return l.illegal(start, fmt.Sprintf("%s literal %q has no digits", base.name, literal))
+	}
+
+	for _, ch := range digits {
+		if ch != '_' && !base.digit(ch) {
+			return l.illegal(start, fmt.Sprintf("invalid digit %q in %s literal %q", ch, base.name, literal))
+		}
+	}
+
+	// an underscore may also follow the prefix, as in 0x_FF
+	if !separatesDigits("0"+digits, base.digit) {
+		return l.illegal(start, fmt.Sprintf("'_' must separate successive digits in %q", literal))
+	}
+	return token.Token{Type: token.INT, Literal: literal}
+}
+
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+// separatesDigits reports whether every underscore in literal stands
+// between two digits.
+func separatesDigits(literal string, digit func(rune) bool) bool {
+	runes := []rune(literal)
+	for i, ch := range runes {
+		if ch != '_' {
+			continue
+		}
+		if i == 0 || i == len(runes)-1 || !digit(runes[i-1]) || !digit(runes[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseInteger reads text as an integer literal, with an optional sign:
+// decimal, even with leading zeros, or hexadecimal, octal and binary
+// with a 0x, 0o or 0b prefix. Underscores may only separate digits.
+func ParseInteger(text string) (*big.Int, bool) {
+	digits := text
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+
+	if len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("xXoObB", rune(digits[1])) {
+		// base 0 reads the prefix and checks the underscores after it
+		return new(big.Int).SetString(text, 0)
+	}
+
+	if digits == "" || strings.TrimFunc(digits, func(ch rune) bool { return isDigit(ch) || ch == '_' }) != "" {
+		return nil, false
+	}
+	if !separatesDigits(digits, isDigit) {
+		return nil, false
+	}
+	return new(big.Int).SetString(strings.ReplaceAll(text, "_", ""), 10)
+}
+
+// readCharLiteral reads a character between single quotes, such as 'a'
+// or '\n'. Its value is the character's code point.
+func (l *Lexer) readCharLiteral() token.Token {
+	start := l.position
+	l.readChar()
+
+	var value strings.Builder
+	problem := ""
+	for l.ch != '\'' {
+		if l.ch == 0 || l.ch == '\n' {
+			return l.illegal(start, "unterminated character literal")
+		}
+
+		if l.ch == '\\' {
+			if err := l.readEscape(&value); err != "" && problem == "" {
+				problem = err
+			}
+			continue
+		}
+
+		value.WriteString(l.input[l.position:l.readPosition])
+		l.readChar()
+	}
+	l.readChar()
+
+	switch {
+	case problem != "":
+		return l.illegal(start, problem)
+	case value.Len() == 0:
+		return l.illegal(start, "empty character literal")
+	case utf8.RuneCountInString(value.String()) > 1:
+		return l.illegal(start, fmt.Sprintf("character literal %s holds more than one character", l.input[start:l.position]))
+	}
+	return token.Token{Type: token.CHAR, Literal: value.String()}
+}
+
+// illegal returns an ILLEGAL token for the input read since start.
+func (l *Lexer) illegal(start int, problem string) token.Token {
+	return token.Token{Type: token.ILLEGAL, Literal: l.input[start:l.position], Error: problem}
 }
 
 func (l *Lexer) readIndentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isIdentifierChar(l.ch) {
 		l.readChar()
 	}
 	return l.input[position:l.position]
@@ -217,14 +726,89 @@ func (l *Lexer) skipWhitespace() {
 	}
 }
 
-func isDigit(ch byte) bool {
+// skipTrivia skips whitespace and comments, returning the comments when
+// they are kept. Line comments run from `//` to the end of the line, and
+// block comments from `/*` to the matching `*/`; block comments nest. A
+// block comment that is never closed is left for scanToken to report.
+func (l *Lexer) skipTrivia() []token.Comment {
+	var comments []token.Comment
+
+	for {
+		l.skipWhitespace()
+		if l.ch != '/' {
+			return comments
+		}
+
+		start := token.Position{FileName: l.fileName, Line: l.line, Column: l.column, Offset: l.position}
+		switch l.peekChar() {
+		case '/':
+			for l.ch != '\n' && l.ch != 0 {
+				l.readChar()
+			}
+		case '*':
+			end := l.blockCommentEnd()
+			if end < 0 {
+				return comments
+			}
+			for l.position < end {
+				l.readChar()
+			}
+		default:
+			return comments
+		}
+
+		if l.keepComments {
+			comments = append(comments, token.Comment{
+				Text:  l.input[start.Offset:l.position],
+				Start: start,
+				End:   token.Position{FileName: l.fileName, Line: l.line, Column: l.column, Offset: l.position},
+			})
+		}
+	}
+}
+
+// blockCommentEnd returns the offset just past the block comment that
+// starts at the current position, or -1 when it is not closed.
+func (l *Lexer) blockCommentEnd() int {
+	depth := 0
+	for i := l.position; i < len(l.input)-1; {
+		switch l.input[i : i+2] {
+		case "/*":
+			depth++
+			i += 2
+		case "*/":
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+func isDigit(ch rune) bool {
 	return '0' <= ch && '9' >= ch
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
+func (l *Lexer) peekChar() rune {
+	return l.peekCharAt(1)
+}
+
+// peekCharAt returns the character n positions after the current one
+// without consuming anything.
+func (l *Lexer) peekCharAt(n int) rune {
+	position := l.readPosition
+	for i := 1; i < n && position < len(l.input); i++ {
+		_, size := utf8.DecodeRuneInString(l.input[position:])
+		position += size
+	}
+
+	if position >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+	ch, _ := utf8.DecodeRuneInString(l.input[position:])
+	return ch
 }
@@ -350,3 +350,597 @@ func TestMultiCharOperators(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `def x = "ab";
+x > 10
+  != 1`
+
+	tests := []struct {
+		expectedType      token.TokenType
+		expectedLine      int
+		expectedColumn    int
+		expectedOffset    int
+		expectedEndColumn int
+	}{
+		{token.DEF, 1, 1, 0, 4},
+		{token.ID, 1, 5, 4, 6},
+		{token.ASSIGN, 1, 7, 6, 8},
+		{token.STRING, 1, 9, 8, 13},
+		{token.SEMICOLON, 1, 13, 12, 14},
+		{token.ID, 2, 1, 14, 2},
+		{token.GREATER, 2, 3, 16, 4},
+		{token.INT, 2, 5, 18, 7},
+		{token.NOT_EQUALITY_SIMPLE, 3, 3, 23, 5},
+		{token.INT, 3, 6, 26, 7},
+		{token.EOF, 3, 7, 27, 7},
+	}
+
+	lexer := NewFile("test.apl", input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.FileName != "test.apl" {
+			t.Fatalf("tests[%d] - filename wrong. expected=%q, got=%q", i, "test.apl", tok.FileName)
+		}
+
+		if tok.LineNumber != test.expectedLine || tok.Column != test.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, test.expectedLine, test.expectedColumn, tok.LineNumber, tok.Column)
+		}
+
+		if tok.Offset != test.expectedOffset {
+			t.Fatalf("tests[%d] - offset wrong. expected=%d, got=%d", i, test.expectedOffset, tok.Offset)
+		}
+
+		if tok.EndColumn != test.expectedEndColumn {
+			t.Fatalf("tests[%d] - end column wrong. expected=%d, got=%d", i, test.expectedEndColumn, tok.EndColumn)
+		}
+	}
+}
+
+func TestComparisonOperators(t *testing.T) {
+	input := `a >= b <= c <> d > e < f`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.ID, "a"},
+		{token.GREATEREQUAL, ">="},
+		{token.ID, "b"},
+		{token.SMALLEREQUAL, "<="},
+		{token.ID, "c"},
+		{token.NOT_EQUALITY_SIGNS, "<>"},
+		{token.ID, "d"},
+		{token.GREATER, ">"},
+		{token.ID, "e"},
+		{token.SMALLER, "<"},
+		{token.ID, "f"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `while (x) { break; continue; }`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.WHILE, "while"},
+		{token.LPARENTHESES, "("},
+		{token.ID, "x"},
+		{token.RPARENTHESES, ")"},
+		{token.LBRACE, "{"},
+		{token.BREAK, "break"},
+		{token.SEMICOLON, ";"},
+		{token.CONTINUE, "continue"},
+		{token.SEMICOLON, ";"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	input := `a && b || not c and d or e & |`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.ID, "a"},
+		{token.AND, "&&"},
+		{token.ID, "b"},
+		{token.OR, "||"},
+		{token.NOT, "not"},
+		{token.ID, "c"},
+		{token.AND, "and"},
+		{token.ID, "d"},
+		{token.OR, "or"},
+		{token.ID, "e"},
+		{token.BITWISE_AND, "&"},
+		{token.BITWISE_OR, "|"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	input := `3.14 .5 1e-9 2E+3 7 1.x 4e x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, ".5"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "2E+3"},
+		{token.INT, "7"},
+		{token.INT, "1"},
+		{token.ILLEGAL, "."},
+		{token.ID, "x"},
+		{token.ILLEGAL, "4e"},
+		{token.ID, "x"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestArithmeticAndBitwiseOperators(t *testing.T) {
+	input := `a % b ** c * d & e | f ^ ~g << 2 >> 1 <= >= <>`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.ID, "a"},
+		{token.PERCENT, "%"},
+		{token.ID, "b"},
+		{token.POWER, "**"},
+		{token.ID, "c"},
+		{token.ASTERISK, "*"},
+		{token.ID, "d"},
+		{token.BITWISE_AND, "&"},
+		{token.ID, "e"},
+		{token.BITWISE_OR, "|"},
+		{token.ID, "f"},
+		{token.BITWISE_XOR, "^"},
+		{token.BITWISE_NOT, "~"},
+		{token.ID, "g"},
+		{token.SHIFT_LEFT, "<<"},
+		{token.INT, "2"},
+		{token.SHIFT_RIGHT, ">>"},
+		{token.INT, "1"},
+		{token.SMALLEREQUAL, "<="},
+		{token.GREATEREQUAL, ">="},
+		{token.NOT_EQUALITY_SIGNS, "<>"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading comment
+def x = 10; // trailing comment
+/* block
+   comment */ x /= 2;
+a /* outer /* nested */ still comment */ / b
+// comment at end`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.DEF, "def"},
+		{token.ID, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.ID, "x"},
+		{token.SHORT_DIVISION, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.ID, "a"},
+		{token.SLASH, "/"},
+		{token.ID, "b"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Comments != nil {
+			t.Fatalf("tests[%d] - comments kept without KeepComments. got=%v", i, tok.Comments)
+		}
+	}
+}
+
+func TestKeepComments(t *testing.T) {
+	input := "// one\n/* two\n */ x // three\n"
+
+	lexer := New(input)
+	lexer.KeepComments()
+
+	x := lexer.NextToken()
+	if x.Type != token.ID || len(x.Comments) != 2 {
+		t.Fatalf("expected identifier with 2 comments. got=%q with %d", x.Type, len(x.Comments))
+	}
+
+	if x.Comments[0].Text != "// one" || x.Comments[1].Text != "/* two\n */" {
+		t.Errorf("comment texts wrong. got=%q, %q", x.Comments[0].Text, x.Comments[1].Text)
+	}
+
+	second := x.Comments[1]
+	if second.Start.Line != 2 || second.Start.Column != 1 || second.End.Line != 3 || second.End.Column != 4 {
+		t.Errorf("comment span wrong. got start=%s, end=%s", second.Start, second.End)
+	}
+
+	eof := lexer.NextToken()
+	if eof.Type != token.EOF || len(eof.Comments) != 1 || eof.Comments[0].Text != "// three" {
+		t.Errorf("trailing comment not attached to EOF. got=%v", eof.Comments)
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	input := "x /* never /* closed */"
+
+	lexer := New(input)
+	lexer.NextToken()
+
+	tok := lexer.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "/* never /* closed */" {
+		t.Fatalf("expected ILLEGAL for unterminated comment. got=%q %q", tok.Type, tok.Literal)
+	}
+
+	if tok.Column != 3 || tok.EndOffset != len(input) {
+		t.Errorf("unterminated comment span wrong. got column=%d, end=%d", tok.Column, tok.EndOffset)
+	}
+
+	if eof := lexer.NextToken(); eof.Type != token.EOF {
+		t.Errorf("expected EOF after unterminated comment. got=%q", eof.Type)
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`"plain"`, token.STRING, "plain", ""},
+		{`""`, token.STRING, "", ""},
+		{`"a\nb\tc\rd"`, token.STRING, "a\nb\tc\rd", ""},
+		{`"say \"hi\""`, token.STRING, `say "hi"`, ""},
+		{`"back\\slash \'q\'"`, token.STRING, `back\slash 'q'`, ""},
+		{`"nul\0"`, token.STRING, "nul\x00", ""},
+		{`"\u{48}\u{e9}\u{1F600}"`, token.STRING, "Hé\U0001F600", ""},
+		{"`raw \\n \"text\"`", token.STRING, `raw \n "text"`, ""},
+		{"`two\nlines`", token.STRING, "two\nlines", ""},
+		{"\"\"\"first \"quoted\"\nsecond\\t\"\"\"", token.STRING, "first \"quoted\"\nsecond\t", ""},
+		{`""""""`, token.STRING, "", ""},
+		{`"bad \q escape"`, token.ILLEGAL, `"bad \q escape"`, `invalid escape sequence "\\q"`},
+		{`"\u{110000}"`, token.ILLEGAL, `"\u{110000}"`, `invalid Unicode code point "\\u{110000}"`},
+		{`"\u{D800}"`, token.ILLEGAL, `"\u{D800}"`, `invalid Unicode code point "\\u{D800}"`},
+		{`"\u{}"`, token.ILLEGAL, `"\u{}"`, `invalid Unicode code point "\\u{}"`},
+		{`"\u41"`, token.ILLEGAL, `"\u41"`, `invalid escape sequence "\\u", expected \u{hex}`},
+		{`"\u{41"`, token.ILLEGAL, `"\u{41"`, `invalid escape sequence "\\u{41", expected \u{hex}`},
+		{`"never closed`, token.ILLEGAL, `"never closed`, "unterminated string"},
+		{"\"ends at newline\n\"", token.ILLEGAL, `"ends at newline`, "unterminated string"},
+		{`"trailing \`, token.ILLEGAL, `"trailing \`, "unterminated string"},
+		{"`never closed", token.ILLEGAL, "`never closed", "unterminated raw string"},
+		{`"""never closed"`, token.ILLEGAL, `"""never closed"`, "unterminated multi-line string"},
+	}
+
+	for _, test := range tests {
+		tok := New(test.input).NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Errorf("%s - tokentype wrong. expected=%q, got=%q", test.input, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Errorf("%s - literal wrong. expected=%q, got=%q", test.input, test.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Error != test.expectedError {
+			t.Errorf("%s - error wrong. expected=%q, got=%q", test.input, test.expectedError, tok.Error)
+		}
+	}
+}
+
+func TestTokensAfterStrings(t *testing.T) {
+	input := "\"a\\\"b\" + `c` + \"\"\"d\ne\"\"\" + \"bad \\q\"; x"
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedLine int
+	}{
+		{token.STRING, 1},
+		{token.PLUS, 1},
+		{token.STRING, 1},
+		{token.PLUS, 1},
+		{token.STRING, 1},
+		{token.PLUS, 2},
+		{token.ILLEGAL, 2},
+		{token.SEMICOLON, 2},
+		{token.ID, 2},
+		{token.EOF, 2},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType || tok.LineNumber != test.expectedLine {
+			t.Fatalf("tests[%d] - wrong token. expected=%q on line %d, got=%q on line %d", i, test.expectedType, test.expectedLine, tok.Type, tok.LineNumber)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"Hello ${name}, ${ {"a": 1}["a"] + 2 }!" "${"in ${x}"}" "\${not}" ` + "`${raw}`"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING_START, "Hello "},
+		{token.ID, "name"},
+		{token.STRING_MIDDLE, ", "},
+		{token.LBRACE, "{"},
+		{token.STRING, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.STRING, "a"},
+		{token.RBRACKET, "]"},
+		{token.PLUS, "+"},
+		{token.INT, "2"},
+		{token.STRING_END, "!"},
+		{token.STRING_START, ""},
+		{token.STRING_START, "in "},
+		{token.ID, "x"},
+		{token.STRING_END, ""},
+		{token.STRING_END, ""},
+		{token.STRING, "${not}"},
+		{token.STRING, "${raw}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+
+		if tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q", i, test.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := "def سلام = \"دنیا 😀\";\nnäme_2 + x1 @ می‌خواهم"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.DEF, "def", 1, 1},
+		{token.ID, "سلام", 1, 5},
+		{token.ASSIGN, "=", 1, 10},
+		{token.STRING, "دنیا 😀", 1, 12},
+		{token.SEMICOLON, ";", 1, 20},
+		{token.ID, "näme_2", 2, 1},
+		{token.PLUS, "+", 2, 8},
+		{token.ID, "x1", 2, 10},
+		{token.ILLEGAL, "@", 2, 13},
+		{token.ID, "می‌خواهم", 2, 15},
+		{token.EOF, "", 2, 23},
+	}
+
+	lexer := New(input)
+
+	for i, test := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q", i, test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok.LineNumber != test.expectedLine || tok.Column != test.expectedColumn {
+			t.Errorf("tests[%d] - wrong position for %q. expected=%d:%d, got=%d:%d", i, tok.Literal, test.expectedLine, test.expectedColumn, tok.LineNumber, tok.Column)
+		}
+
+		if input[tok.Offset:tok.EndOffset] != test.expectedLiteral && tok.Type != token.STRING {
+			t.Errorf("tests[%d] - offsets do not span %q. got=%q", i, test.expectedLiteral, input[tok.Offset:tok.EndOffset])
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	lexer := New("x \xff \"a\xfeb\"")
+
+	lexer.NextToken()
+	tok := lexer.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "\xff" || tok.Error != "invalid UTF-8 encoding" {
+		t.Errorf("invalid byte wrong. got=%q %q %q", tok.Type, tok.Literal, tok.Error)
+	}
+
+	str := lexer.NextToken()
+	if str.Type != token.STRING || str.Literal != "a\xfeb" {
+		t.Errorf("bytes in string not kept. got=%q %q", str.Type, str.Literal)
+	}
+}
+
+func TestPrefixedAndSeparatedNumbers(t *testing.T) {
+	input := `0xFF 0XaB_cd 0o755 0b1010 0b_1 1_000_000 3.141_592 1_0e1_0 007`
+
+	expected := []string{"0xFF", "0XaB_cd", "0o755", "0b1010", "0b_1", "1_000_000", "3.141_592", "1_0e1_0", "007"}
+
+	lexer := New(input)
+	for i, literal := range expected {
+		tok := lexer.NextToken()
+		if (tok.Type != token.INT && tok.Type != token.FLOAT) || tok.Literal != literal {
+			t.Fatalf("tests[%d] - wrong token. expected=%q, got=%q %q (%s)", i, literal, tok.Type, tok.Literal, tok.Error)
+		}
+	}
+
+	if tok := lexer.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF, got=%q", tok.Type)
+	}
+}
+
+func TestMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string
+	}{
+		{"0x", "0x", `hexadecimal literal "0x" has no digits`},
+		{"0b_", "0b_", `binary literal "0b_" has no digits`},
+		{"0b102", "0b102", `invalid digit '2' in binary literal "0b102"`},
+		{"0o78", "0o78", `invalid digit '8' in octal literal "0o78"`},
+		{"0xFG", "0xFG", `invalid digit 'G' in hexadecimal literal "0xFG"`},
+		{"12abc", "12abc", `malformed number literal "12abc"`},
+		{"1.5x", "1.5x", `malformed number literal "1.5x"`},
+		{"1__000", "1__000", `'_' must separate successive digits in "1__000"`},
+		{"100_", "100_", `'_' must separate successive digits in "100_"`},
+		{"1_.5", "1_.5", `'_' must separate successive digits in "1_.5"`},
+		{"0xFF_", "0xFF_", `'_' must separate successive digits in "0xFF_"`},
+	}
+
+	for _, test := range tests {
+		lexer := New(test.input + " x")
+		tok := lexer.NextToken()
+
+		if tok.Type != token.ILLEGAL || tok.Literal != test.expectedLiteral || tok.Error != test.expectedError {
+			t.Errorf("%q - wrong token. expected=ILLEGAL %q %q, got=%q %q %q",
+				test.input, test.expectedLiteral, test.expectedError, tok.Type, tok.Literal, tok.Error)
+		}
+
+		if next := lexer.NextToken(); next.Type != token.ID || next.Literal != "x" {
+			t.Errorf("%q - lexing did not resume. got=%q %q", test.input, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestCharLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`'a'`, token.CHAR, "a", ""},
+		{`'\n'`, token.CHAR, "\n", ""},
+		{`'\''`, token.CHAR, "'", ""},
+		{`'"'`, token.CHAR, `"`, ""},
+		{`'\u{1F600}'`, token.CHAR, "😀", ""},
+		{`'é'`, token.CHAR, "é", ""},
+		{`''`, token.ILLEGAL, "''", "empty character literal"},
+		{`'ab'`, token.ILLEGAL, "'ab'", "character literal 'ab' holds more than one character"},
+		{`'\q'`, token.ILLEGAL, `'\q'`, `invalid escape sequence "\\q"`},
+		{`'a`, token.ILLEGAL, "'a", "unterminated character literal"},
+	}
+
+	for _, test := range tests {
+		tok := New(test.input).NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral || tok.Error != test.expectedError {
+			t.Errorf("%s - wrong token. expected=%q %q %q, got=%q %q %q", test.input,
+				test.expectedType, test.expectedLiteral, test.expectedError, tok.Type, tok.Literal, tok.Error)
+		}
+	}
+}
@@ -2,9 +2,77 @@ package main
 
 import (
 	"Ahmadi/repl"
+	"Ahmadi/runner"
+	"fmt"
+	"io"
 	"os"
 )
 
+const usage = `usage:
+  apl                        start the REPL (or run a program piped on stdin)
+  apl run <file> [args...]   run a program file
+  apl <file> [args...]       same as run
+  apl -e <code> [args...]    evaluate code and print the result
+`
+
 func main() {
-	repl.Start(os.Stdin, os.Stdout)
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		if isTerminal(os.Stdin) {
+			repl.Start(os.Stdin, os.Stdout)
+			return runner.ExitOK
+		}
+
+		source, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "apl: %s\n", err)
+			return runner.ExitRuntimeError
+		}
+		return runner.Run(string(source), runner.Options{FileName: "<stdin>"}, os.Stdout, os.Stderr)
+	}
+
+	switch args[0] {
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, usage)
+		return runner.ExitOK
+
+	case "-e":
+		if len(args) < 2 {
+			fmt.Fprint(os.Stderr, usage)
+			return runner.ExitSyntaxError
+		}
+		options := runner.Options{FileName: "<expr>", Args: args[2:], PrintResult: true}
+		return runner.Run(args[1], options, os.Stdout, os.Stderr)
+
+	case "run":
+		if len(args) < 2 {
+			fmt.Fprint(os.Stderr, usage)
+			return runner.ExitSyntaxError
+		}
+		return runFile(args[1], args[2:])
+
+	default:
+		return runFile(args[0], args[1:])
+	}
+}
+
+func runFile(path string, args []string) int {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "apl: %s\n", err)
+		return runner.ExitRuntimeError
+	}
+
+	return runner.Run(string(source), runner.Options{FileName: path, Args: args}, os.Stdout, os.Stderr)
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
 }
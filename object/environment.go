@@ -1,15 +1,20 @@
 package object
 
+import "sort"
+
 func NewEnvironment() *Environment {
 	return &Environment{
-		store: make(map[string]Object),
-		outer: nil,
+		store:   make(map[string]Object),
+		outer:   nil,
+		nesting: new(int),
 	}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	depth   int  // number of function calls active when the scope was created
+	nesting *int // evaluations in progress, shared by the scopes of one evaluation
 }
 
 func (environment *Environment) Get(name string) (Object, bool) {
@@ -25,8 +30,66 @@ func (environment *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign rebinds name in the nearest scope that already defines it. It
+// reports false, leaving every scope untouched, when name is unbound.
+func (environment *Environment) Assign(name string, val Object) (Object, bool) {
+	for env := environment; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return val, true
+		}
+	}
+	return nil, false
+}
+
 func NewEncloseEnvironment(outer *Environment) *Environment {
 	env := NewEnvironment()
 	env.outer = outer
+	env.depth = outer.depth
+	env.nesting = outer.nesting
 	return env
 }
+
+// NewCallEnvironment creates the scope for a function call: names
+// resolve through closure, the scope the function was defined in, while
+// the call depth continues from caller.
+func NewCallEnvironment(closure *Environment, caller *Environment) *Environment {
+	env := NewEncloseEnvironment(closure)
+	env.depth = caller.depth + 1
+	env.nesting = caller.nesting
+	return env
+}
+
+// Depth returns how many function calls are active in this scope.
+func (environment *Environment) Depth() int {
+	return environment.depth
+}
+
+// Enter records that evaluation went one level deeper and returns how
+// many levels are now in progress. Every Enter is paired with a Leave.
+func (environment *Environment) Enter() int {
+	*environment.nesting++
+	return *environment.nesting
+}
+
+// Leave undoes the matching Enter.
+func (environment *Environment) Leave() {
+	*environment.nesting--
+}
+
+// Names returns the sorted names visible from this scope, including the
+// ones bound in enclosing scopes.
+func (environment *Environment) Names() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for env := environment; env != nil; env = env.outer {
+		for name := range env.store {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -2,14 +2,21 @@ package object
 
 import (
 	"Ahmadi/ast"
+	"Ahmadi/token"
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
+	BIGINT_OBJ       = "BIGINT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -19,6 +26,8 @@ const (
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 )
 
 type ObjectType string
@@ -36,6 +45,30 @@ type Integer struct {
 func (integer *Integer) Inspect() string  { return fmt.Sprintf("%d", integer.Value) }
 func (integer *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// BigInt holds integers that do not fit in 64 bits. Values that fit are
+// always represented as Integer, so each number has one representation.
+type BigInt struct {
+	Value *big.Int
+}
+
+func (bigInt *BigInt) Inspect() string  { return bigInt.Value.String() }
+func (bigInt *BigInt) Type() ObjectType { return BIGINT_OBJ }
+
+type Float struct {
+	Value float64
+}
+
+// Inspect always shows a decimal point or an exponent, so that floats
+// with integral values are not mistaken for integers.
+func (float *Float) Inspect() string {
+	text := strconv.FormatFloat(float.Value, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".eIN") {
+		text += ".0"
+	}
+	return text
+}
+func (float *Float) Type() ObjectType { return FLOAT_OBJ }
+
 type Boolean struct {
 	Value bool
 }
@@ -55,14 +88,75 @@ type ReturnValue struct {
 func (returnValue *ReturnValue) Inspect() string  { return returnValue.Value.Inspect() }
 func (returnValue *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
+// Break and Continue unwind the statements of a loop body, the same way
+// ReturnValue unwinds a function body.
+type Break struct{}
+
+func (b *Break) Inspect() string  { return "break" }
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+
+type Continue struct{}
+
+func (c *Continue) Inspect() string  { return "continue" }
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+
+// MaxTracebackFrames bounds how many frames an Error keeps, so that an
+// error raised by runaway recursion stays readable.
+const MaxTracebackFrames = 64
+
+// Frame is one function call an error unwound through: the name the
+// function was bound to with def, if any, and where it was called.
+type Frame struct {
+	Function string
+	Position token.Position
+}
+
 type Error struct {
-	Message string
+	Message  string
+	Position token.Position // where the error was raised; zero if unknown
+	Stack    []Frame        // innermost call first
+	Dropped  int            // frames left out of Stack past MaxTracebackFrames
 }
 
 func (err *Error) Inspect() string  { return "Error: " + err.Message }
 func (err *Error) Type() ObjectType { return ERROR_OBJ }
 
+// AddFrame records that the error unwound through a call.
+func (err *Error) AddFrame(frame Frame) {
+	if len(err.Stack) >= MaxTracebackFrames {
+		err.Dropped++
+		return
+	}
+	err.Stack = append(err.Stack, frame)
+}
+
+// Traceback renders the error with its position and the calls it
+// unwound through, most recent call first.
+func (err *Error) Traceback() string {
+	var out bytes.Buffer
+
+	out.WriteString(err.Inspect())
+	if err.Position.Line > 0 {
+		out.WriteString("\n    at " + err.Position.String())
+	}
+
+	for _, frame := range err.Stack {
+		name := frame.Function
+		if name == "" {
+			name = "<anonymous>"
+		}
+		out.WriteString(fmt.Sprintf("\n    in %s called at %s", name, frame.Position))
+	}
+
+	if err.Dropped > 0 {
+		out.WriteString(fmt.Sprintf("\n    ... %d more calls", err.Dropped))
+	}
+
+	return out.String()
+}
+
 type Function struct {
+	Name       string // the name given by the def that first bound it
 	Parameters []*ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
@@ -153,6 +247,38 @@ func (integer *Integer) HashKey() HashKey {
 	}
 }
 
+func (bigInt *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	if bigInt.Value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	h.Write(bigInt.Value.Bytes())
+
+	return HashKey{
+		Type:  bigInt.Type(),
+		Value: h.Sum64(),
+	}
+}
+
+// HashKey of a float with an integral value is the key of the equal
+// integer, as 1 == 1.0 makes {1: "x"}[1.0] find "x". That also gives
+// 0.0 and -0.0 the same key.
+func (float *Float) HashKey() HashKey {
+	value := float.Value
+	if value == math.Trunc(value) && !math.IsInf(value, 0) {
+		if value >= math.MinInt64 && value < math.MaxInt64 {
+			return (&Integer{Value: int64(value)}).HashKey()
+		}
+		integer, _ := new(big.Float).SetFloat64(value).Int(nil)
+		return (&BigInt{Value: integer}).HashKey()
+	}
+
+	return HashKey{
+		Type:  float.Type(),
+		Value: math.Float64bits(value),
+	}
+}
+
 func (str *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(str.Value))
@@ -173,6 +299,45 @@ type Hash struct {
 }
 
 func (hash *Hash) Type() ObjectType { return HASH_OBJ }
+
+// SortedPairs returns the pairs of the hash in a deterministic order:
+// grouped by key type, then ordered by key value within each type.
+func (hash *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(hash.Pairs))
+	for _, pair := range hash.Pairs {
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		return lessKey(pairs[i].Key, pairs[j].Key)
+	})
+	return pairs
+}
+
+func lessKey(a, b Object) bool {
+	if a.Type() != b.Type() {
+		return a.Type() < b.Type()
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		return a.Value < b.(*Integer).Value
+	case *BigInt:
+		return a.Value.Cmp(b.(*BigInt).Value) < 0
+	case *Float:
+		// NaN is unordered under <, so it sorts after every other float
+		x, y := a.Value, b.(*Float).Value
+		if math.IsNaN(x) || math.IsNaN(y) {
+			return !math.IsNaN(x)
+		}
+		return x < y
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	default:
+		return a.Inspect() < b.Inspect()
+	}
+}
+
 func (hash *Hash) Inspect() string {
 	var out bytes.Buffer
 
@@ -1,6 +1,10 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	t.Parallel()
@@ -26,3 +30,155 @@ func TestStringHashKey(t *testing.T) {
 		t.Error("strings with different content have same hash keys")
 	}
 }
+
+func TestHashSortedPairs(t *testing.T) {
+	t.Parallel()
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	keys := []Object{
+		&String{Value: "b"},
+		&Integer{Value: 10},
+		&String{Value: "a"},
+		&Boolean{Value: true},
+		&Integer{Value: -3},
+		&Boolean{Value: false},
+	}
+
+	for _, key := range keys {
+		hash.Pairs[key.(Hashable).HashKey()] = HashPair{Key: key, Value: key}
+	}
+
+	expected := []string{"false", "true", "-3", "10", "a", "b"}
+	pairs := hash.SortedPairs()
+
+	if len(pairs) != len(expected) {
+		t.Fatalf("wrong number of pairs. expected=%d, got=%d", len(expected), len(pairs))
+	}
+
+	for i, pair := range pairs {
+		if pair.Key.Inspect() != expected[i] {
+			t.Errorf("pairs[%d] has wrong key. expected=%q, got=%q", i, expected[i], pair.Key.Inspect())
+		}
+	}
+}
+
+func TestHashSortedPairsWithNaN(t *testing.T) {
+	t.Parallel()
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	keys := []Object{
+		&Float{Value: math.NaN()},
+		&Float{Value: 2.5},
+		&Float{Value: math.Float64frombits(0x7ff8000000000002)},
+		&Float{Value: -1.5},
+		&Float{Value: math.Inf(1)},
+		&Float{Value: 0.5},
+	}
+
+	for _, key := range keys {
+		hash.Pairs[key.(Hashable).HashKey()] = HashPair{Key: key, Value: key}
+	}
+
+	expected := []string{"-1.5", "0.5", "2.5", "+Inf", "NaN", "NaN"}
+
+	// a comparator that is not a strict weak ordering only misorders
+	// some of the time, so sort more than once
+	for round := 0; round < 20; round++ {
+		pairs := hash.SortedPairs()
+
+		if len(pairs) != len(expected) {
+			t.Fatalf("wrong number of pairs. expected=%d, got=%d", len(expected), len(pairs))
+		}
+
+		for i, pair := range pairs {
+			if pair.Key.Inspect() != expected[i] {
+				t.Fatalf("pairs[%d] has wrong key. expected=%q, got=%q", i, expected[i], pair.Key.Inspect())
+			}
+		}
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3.14, "3.14"},
+		{2, "2.0"},
+		{-0.5, "-0.5"},
+		{1e21, "1e+21"},
+		{math.Inf(1), "+Inf"},
+	}
+
+	for _, test := range tests {
+		float := &Float{Value: test.value}
+		if float.Inspect() != test.expected {
+			t.Errorf("wrong Inspect for %g. expected=%q, got=%q", test.value, test.expected, float.Inspect())
+		}
+	}
+}
+
+func TestBigIntHashKey(t *testing.T) {
+	t.Parallel()
+	value, _ := new(big.Int).SetString("18446744073709551616", 10)
+	same, _ := new(big.Int).SetString("18446744073709551616", 10)
+	negative := new(big.Int).Neg(value)
+
+	if (&BigInt{Value: value}).HashKey() != (&BigInt{Value: same}).HashKey() {
+		t.Error("big integers with same value have different hash keys")
+	}
+
+	if (&BigInt{Value: value}).HashKey() == (&BigInt{Value: negative}).HashKey() {
+		t.Error("big integers with opposite signs have same hash keys")
+	}
+}
+
+func TestNumberHashKeys(t *testing.T) {
+	t.Parallel()
+	big64, _ := new(big.Int).SetString("9223372036854775808", 10)
+
+	same := []struct {
+		number Hashable
+		float  float64
+	}{
+		{&Integer{Value: 1}, 1},
+		{&Integer{Value: -7}, -7},
+		{&Integer{Value: 0}, math.Copysign(0, -1)},
+		{&Integer{Value: math.MinInt64}, math.MinInt64},
+		{&BigInt{Value: big64}, 9223372036854775808},
+	}
+
+	for _, test := range same {
+		if test.number.HashKey() != (&Float{Value: test.float}).HashKey() {
+			t.Errorf("%g has a different hash key than the equal %s", test.float, test.number.(Object).Inspect())
+		}
+	}
+
+	if (&Float{Value: 1.5}).HashKey() == (&Integer{Value: 1}).HashKey() {
+		t.Error("1.5 and 1 have the same hash key")
+	}
+
+	if (&Float{Value: math.Inf(1)}).HashKey() != (&Float{Value: math.Inf(1)}).HashKey() {
+		t.Error("infinities have different hash keys")
+	}
+}
+
+func TestEnvironmentNames(t *testing.T) {
+	t.Parallel()
+	outer := NewEnvironment()
+	outer.Set("b", &Integer{Value: 1})
+	outer.Set("a", &Integer{Value: 2})
+	inner := NewEncloseEnvironment(outer)
+	inner.Set("c", &Integer{Value: 3})
+	inner.Set("a", &Integer{Value: 4})
+
+	names := inner.Names()
+	expected := []string{"a", "b", "c"}
+	if len(names) != len(expected) {
+		t.Fatalf("wrong number of names. expected=%v, got=%v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("names[%d] wrong. expected=%q, got=%q", i, name, names[i])
+		}
+	}
+}
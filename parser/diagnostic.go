@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"Ahmadi/token"
+	"fmt"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Diagnostic describes a problem found while parsing. Start and End
+// delimit the offending source; Expected and Found are set when the
+// parser wanted a particular token and saw another one.
+type Diagnostic struct {
+	Severity Severity
+	Message  string
+	Start    token.Position
+	End      token.Position
+	Expected token.TokenType
+	Found    token.TokenType
+	Hint     string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.Start, d.Severity, d.Message)
+}
+
+// Snippet renders the source line the diagnostic points at with the
+// offending span underlined by carets. It returns an empty string when
+// the position does not fall inside source.
+func (d Diagnostic) Snippet(source string) string {
+	return d.SnippetFunc(source, func(line string) string { return line })
+}
+
+// SnippetFunc is Snippet with the quoted source line passed through
+// render, which may add styling such as colors.
+func (d Diagnostic) SnippetFunc(source string, render func(line string) string) string {
+	lines := strings.Split(source, "\n")
+	if d.Start.Line < 1 || d.Start.Line > len(lines) {
+		return ""
+	}
+
+	line := strings.TrimRight(lines[d.Start.Line-1], "\r")
+	width := 1
+	if d.End.Line == d.Start.Line && d.End.Column > d.Start.Column {
+		width = d.End.Column - d.Start.Column
+	}
+
+	var out strings.Builder
+	gutter := fmt.Sprintf("%d | ", d.Start.Line)
+
+	out.WriteString(gutter)
+	out.WriteString(render(line))
+	out.WriteString("\n")
+	out.WriteString(strings.Repeat(" ", len(gutter)))
+	for i, ch := range []rune(line) {
+		if i >= d.Start.Column-1 {
+			break
+		}
+		if ch == '\t' {
+			out.WriteByte('\t')
+		} else {
+			out.WriteByte(' ')
+		}
+	}
+	out.WriteString(strings.Repeat("^", width))
+
+	if d.Hint != "" {
+		out.WriteString(" " + d.Hint)
+	}
+
+	return out.String()
+}
@@ -6,21 +6,37 @@ import (
 	"Ahmadi/token"
 	"fmt"
 	"strconv"
+	"unicode/utf8"
 )
 
 const (
 	_ int = iota
 	LOWEST
+	ASSIGNMENT  // = or +=
+	LOGICAL_OR  // || or
+	LOGICAL_AND // && and
 	EQUALS      // ==
-	LESSGREATER // > or <
+	LESSGREATER // > or < or >= or <=
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
+	SHIFT       // << or >>
 	SUM         // +
-	PRODUCT     // *
-	PREFIX      // -x or !x
+	PRODUCT     // * or / or %
+	PREFIX      // -x or !x or ~x
+	POWER       // **
 	CALL        // func()
 	INDEX
 )
 
 var precedences = map[token.TokenType]int{
+	token.ASSIGN:              ASSIGNMENT,
+	token.SHORT_PLUS:          ASSIGNMENT,
+	token.SHORT_MINUS:         ASSIGNMENT,
+	token.SHORT_MULTIPLY:      ASSIGNMENT,
+	token.SHORT_DIVISION:      ASSIGNMENT,
+	token.OR:                  LOGICAL_OR,
+	token.AND:                 LOGICAL_AND,
 	token.EQUALITY:            EQUALS,
 	token.NOT_EQUALITY_SIMPLE: EQUALS,
 	token.NOT_EQUALITY_SIGNS:  EQUALS,
@@ -28,23 +44,52 @@ var precedences = map[token.TokenType]int{
 	token.SMALLER:             LESSGREATER,
 	token.GREATEREQUAL:        LESSGREATER,
 	token.SMALLEREQUAL:        LESSGREATER,
+	token.BITWISE_OR:          BIT_OR,
+	token.BITWISE_XOR:         BIT_XOR,
+	token.BITWISE_AND:         BIT_AND,
+	token.SHIFT_LEFT:          SHIFT,
+	token.SHIFT_RIGHT:         SHIFT,
 	token.PLUS:                SUM,
 	token.MINUS:               SUM,
 	token.SLASH:               PRODUCT,
 	token.ASTERISK:            PRODUCT,
+	token.PERCENT:             PRODUCT,
+	token.POWER:               POWER,
 	token.LPARENTHESES:        CALL,
 	token.LBRACKET:            INDEX,
 }
 
+// MaxNestingDepth bounds how deeply expressions and blocks may nest, so
+// that pathological input is a syntax error instead of overflowing the
+// Go stack, which is fatal.
+const MaxNestingDepth = 10000
+
 type Parser struct {
 	lexer          *lexer.Lexer
 	curToken       token.Token
 	peekToken      token.Token
-	errors         []string
+	diagnostics    []Diagnostic
+	panicking      bool
+	loopDepth      int
+	braceDepth     int
+	syncDepth      int
+	nesting        int // expressions and blocks being parsed, one inside the other
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
 }
 
+// statementKeywords are the tokens the parser resynchronizes on after
+// a syntax error.
+var statementKeywords = map[token.TokenType]bool{
+	token.DEF:      true,
+	token.RETURN:   true,
+	token.IF:       true,
+	token.WHILE:    true,
+	token.FOR:      true,
+	token.BREAK:    true,
+	token.CONTINUE: true,
+}
+
 type (
 	prefixParseFn func() ast.Expression
 	infixParseFn  func(ast.Expression) ast.Expression
@@ -52,21 +97,26 @@ type (
 
 func New(lexer *lexer.Lexer) *Parser {
 	p := &Parser{
-		lexer:  lexer,
-		errors: []string{},
+		lexer:       lexer,
+		diagnostics: []Diagnostic{},
 	}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.ID, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.CHAR, p.parseCharLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.NOT, p.parsePrefixExpression)
+	p.registerPrefix(token.BITWISE_NOT, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPARENTHESES, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.STRING_START, p.parseInterpolatedString)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
@@ -75,12 +125,28 @@ func New(lexer *lexer.Lexer) *Parser {
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.BITWISE_AND, p.parseInfixExpression)
+	p.registerInfix(token.BITWISE_OR, p.parseInfixExpression)
+	p.registerInfix(token.BITWISE_XOR, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(token.SHIFT_RIGHT, p.parseInfixExpression)
 	p.registerInfix(token.EQUALITY, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQUALITY_SIMPLE, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQUALITY_SIGNS, p.parseInfixExpression)
 	p.registerInfix(token.GREATER, p.parseInfixExpression)
 	p.registerInfix(token.SMALLER, p.parseInfixExpression)
+	p.registerInfix(token.GREATEREQUAL, p.parseInfixExpression)
+	p.registerInfix(token.SMALLEREQUAL, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
 	p.registerInfix(token.LPARENTHESES, p.parseCallExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SHORT_PLUS, p.parseAssignExpression)
+	p.registerInfix(token.SHORT_MINUS, p.parseAssignExpression)
+	p.registerInfix(token.SHORT_MULTIPLY, p.parseAssignExpression)
+	p.registerInfix(token.SHORT_DIVISION, p.parseAssignExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
 
 	p.nextToken() // set peekToken
@@ -175,9 +241,68 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	}
 }
 
+// parseInterpolatedString parses the segments of a string with embedded
+// expressions, from STRING_START to STRING_END.
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken}
+	str.Parts = appendStringSegment(str.Parts, p.curToken)
+
+	for {
+		if p.peekTokenIs(token.STRING_MIDDLE) || p.peekTokenIs(token.STRING_END) {
+			diagnostic := newDiagnostic(p.peekToken, "empty interpolation in string")
+			diagnostic.Hint = "expected an expression between ${ and }"
+			p.report(diagnostic)
+			return nil
+		}
+
+		p.nextToken()
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.STRING_MIDDLE) {
+			p.nextToken()
+			str.Parts = appendStringSegment(str.Parts, p.curToken)
+			continue
+		}
+
+		if p.peekTokenIs(token.ILLEGAL) && p.peekToken.Error != "" {
+			// the rest of the string is malformed, e.g. a bad escape
+			p.report(newDiagnostic(p.peekToken, p.peekToken.Error))
+			return nil
+		}
+
+		if !p.peekTokenIs(token.STRING_END) {
+			message := fmt.Sprintf("expected '}' to close interpolation in string, got='%s'", p.peekToken.Type)
+			diagnostic := newDiagnostic(p.peekToken, message)
+			diagnostic.Expected = token.STRING_END
+			p.report(diagnostic)
+			return nil
+		}
+
+		p.nextToken()
+		str.Parts = appendStringSegment(str.Parts, p.curToken)
+		return str
+	}
+}
+
+// appendStringSegment adds the text of a string segment token to parts,
+// leaving out empty ones.
+func appendStringSegment(parts []ast.Expression, tok token.Token) []ast.Expression {
+	if tok.Literal == "" {
+		return parts
+	}
+	return append(parts, &ast.StringLiteral{Token: tok, Value: tok.Literal})
+}
+
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
 	p.peekToken = p.lexer.NextToken()
+
+	switch {
+	case p.curTokenIs(token.LBRACE):
+		p.braceDepth++
+	case p.curTokenIs(token.RBRACE) && p.braceDepth > 0:
+		p.braceDepth--
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -186,7 +311,9 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 	for !p.curTokenIs(token.EOF) {
 		statement := p.parseStatement()
-		if statement != nil {
+		if p.panicking {
+			p.synchronize()
+		} else if statement != nil {
 			program.Statements = append(program.Statements, statement)
 		}
 
@@ -195,12 +322,39 @@ func (p *Parser) ParseProgram() *ast.Program {
 	return program
 }
 
+// synchronize skips tokens after a syntax error until the end of the
+// broken statement, so that parsing can resume with the next one. It
+// stops on a ';' or before a statement keyword in the enclosing
+// statement list, and before the '}' that closes the enclosing block.
+func (p *Parser) synchronize() {
+	defer func() { p.panicking = false }()
+
+	for !p.curTokenIs(token.EOF) && p.braceDepth >= p.syncDepth {
+		if p.braceDepth == p.syncDepth {
+			if p.curTokenIs(token.SEMICOLON) || statementKeywords[p.peekToken.Type] {
+				return
+			}
+			if p.syncDepth > 0 && p.peekTokenIs(token.RBRACE) {
+				return
+			}
+		}
+
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.curToken.Type {
 	case token.DEF:
 		return p.parseDefStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK, token.CONTINUE:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -215,7 +369,106 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	statement.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return statement
+}
+
+func (p *Parser) parseWhileStatement() ast.Statement {
+	statement := &ast.WhileStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LPARENTHESES) {
+		return nil
+	}
+
+	p.nextToken()
+	statement.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPARENTHESES) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.loopDepth++
+	statement.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+func (p *Parser) parseForStatement() ast.Statement {
+	statement := &ast.ForStatement{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LPARENTHESES) {
+		return nil
+	}
+
+	if !p.expectPeek(token.ID) {
+		return nil
+	}
+	statement.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.ID) {
+			return nil
+		}
+		statement.Key = statement.Value
+		statement.Value = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if !p.expectPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+	statement.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPARENTHESES) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.loopDepth++
+	statement.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return statement
+}
+
+// parseLoopControlStatement parses `break` and `continue`, which are only
+// valid inside the body of a loop.
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	var statement ast.Statement
+	if p.curTokenIs(token.BREAK) {
+		statement = &ast.BreakStatement{Token: p.curToken}
+	} else {
+		statement = &ast.ContinueStatement{Token: p.curToken}
+	}
+
+	if p.loopDepth == 0 {
+		p.report(newDiagnostic(p.curToken, fmt.Sprintf("%s outside loop", p.curToken.Literal)))
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 	return statement
@@ -242,7 +495,7 @@ func (p *Parser) parseDefStatement() *ast.DefStatement {
 	p.nextToken()
 	statement.Value = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -266,13 +519,74 @@ func (p *Parser) expectPeek(t token.TokenType) bool {
 	return false
 }
 
+// Diagnostics returns every problem found while parsing, in the order
+// they were reported.
+func (p *Parser) Diagnostics() []Diagnostic {
+	return p.diagnostics
+}
+
+// Errors returns the diagnostic messages without position information.
 func (p *Parser) Errors() []string {
-	return p.errors
+	messages := make([]string, 0, len(p.diagnostics))
+	for _, diagnostic := range p.diagnostics {
+		messages = append(messages, diagnostic.Message)
+	}
+	return messages
+}
+
+func newDiagnostic(tok token.Token, message string) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Message:  message,
+		Start:    tok.Pos(),
+		End:      tok.End(),
+		Found:    tok.Type,
+	}
+}
+
+// report records a diagnostic and puts the parser in panic mode. While
+// panicking, further diagnostics are follow-up errors of the first one
+// and are dropped until the parser resynchronizes.
+func (p *Parser) report(diagnostic Diagnostic) {
+	if p.panicking {
+		return
+	}
+	p.panicking = true
+	p.diagnostics = append(p.diagnostics, diagnostic)
+}
+
+// enter descends one level of nesting. It reports false, recording a
+// diagnostic, when the input nests deeper than MaxNestingDepth; the
+// caller must then stop descending. Every enter is paired with a leave.
+func (p *Parser) enter() bool {
+	p.nesting++
+	if p.nesting <= MaxNestingDepth {
+		return true
+	}
+
+	diagnostic := newDiagnostic(p.curToken, "input is nested too deeply")
+	diagnostic.Hint = fmt.Sprintf("at most %d levels of nested expressions and blocks are supported", MaxNestingDepth)
+	p.report(diagnostic)
+	return false
+}
+
+func (p *Parser) leave() {
+	p.nesting--
 }
 
 func (p *Parser) peekError(t token.TokenType) {
-	message := fmt.Sprintf("expected next token to be '%s', got='%s'", t, p.peekToken.Type)
-	p.errors = append(p.errors, message)
+	p.expectedError(p.peekToken, t)
+}
+
+func (p *Parser) expectedError(tok token.Token, t token.TokenType) {
+	message := fmt.Sprintf("expected next token to be '%s', got='%s'", t, tok.Type)
+	diagnostic := newDiagnostic(tok, message)
+	diagnostic.Expected = t
+
+	if tok.Type == token.EOF {
+		diagnostic.Hint = fmt.Sprintf("missing '%s' before end of input", t)
+	}
+	p.report(diagnostic)
 }
 
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
@@ -297,6 +611,11 @@ func (p *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
+	defer p.leave()
+	if !p.enter() {
+		return nil
+	}
+
 	prefix := p.prefixParseFns[p.curToken.Type]
 
 	if prefix == nil {
@@ -306,7 +625,9 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 	leftExp := prefix()
 
-	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+	// once an error is reported, synchronize skips the rest of the
+	// expression; parsing on would only build a tree that is thrown away
+	for !p.panicking && !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -330,12 +651,33 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 		Token: p.curToken,
 	}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	value, ok := lexer.ParseInteger(p.curToken.Literal)
+	if !ok {
+		p.report(newDiagnostic(p.curToken, fmt.Sprintf("could not parse %q as integer", p.curToken.Literal)))
+		return nil
+	}
+
+	if value.IsInt64() {
+		literal.Value = value.Int64()
+	} else {
+		literal.Big = value
+	}
+	return literal
+}
+
+func (p *Parser) parseCharLiteral() ast.Expression {
+	value, _ := utf8.DecodeRuneInString(p.curToken.Literal)
+	return &ast.CharLiteral{Token: p.curToken, Value: value}
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	literal := &ast.FloatLiteral{
+		Token: p.curToken,
+	}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
 	if err != nil {
-		p.errors = append(
-			p.errors,
-			fmt.Sprintf("could not parse %q as integer", p.curToken.Literal),
-		)
+		p.report(newDiagnostic(p.curToken, fmt.Sprintf("could not parse %q as float", p.curToken.Literal)))
 		return nil
 	}
 
@@ -344,8 +686,18 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	if t == token.ILLEGAL && p.curToken.Error != "" {
+		p.report(newDiagnostic(p.curToken, p.curToken.Error))
+		return
+	}
+
 	message := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, message)
+	diagnostic := newDiagnostic(p.curToken, message)
+
+	if t == token.ILLEGAL {
+		diagnostic.Hint = fmt.Sprintf("unexpected character %q", p.curToken.Literal)
+	}
+	p.report(diagnostic)
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
@@ -354,8 +706,15 @@ func (p *Parser) parsePrefixExpression() ast.Expression {
 		Operator: p.curToken.Literal,
 	}
 
+	precedence := PREFIX
+	if expression.Token.Type == token.NOT {
+		// the keyword binds looser than comparisons, as in Python:
+		// not a == b is not (a == b), while !a == b is (!a) == b
+		precedence = LOGICAL_AND
+	}
+
 	p.nextToken()
-	expression.Right = p.parseExpression(PREFIX)
+	expression.Right = p.parseExpression(precedence)
 	return expression
 }
 
@@ -381,11 +740,37 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if expression.Token.Type == token.POWER {
+		// ** is right associative: a ** b ** c is a ** (b ** c)
+		precedence--
+	}
+
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 	return expression
 }
 
+func (p *Parser) parseAssignExpression(left ast.Expression) ast.Expression {
+	name, ok := left.(*ast.Identifier)
+	if !ok {
+		diagnostic := newDiagnostic(p.curToken, fmt.Sprintf("cannot assign to %s", left))
+		diagnostic.Hint = "only identifiers can be assigned"
+		p.report(diagnostic)
+		return nil
+	}
+
+	expression := &ast.AssignExpression{
+		Token:    p.curToken,
+		Name:     name,
+		Operator: p.curToken.Literal,
+	}
+
+	// assignment is right associative: a = b = c is a = (b = c)
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGNMENT - 1)
+	return expression
+}
+
 func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{
 		Token: p.curToken,
@@ -424,6 +809,16 @@ func (p *Parser) parseIfExpression() ast.Expression {
 
 	expression.Consequence = p.parseBlockStatement()
 
+	for p.peekTokenIs(token.ELIF) {
+		p.nextToken()
+
+		elif := p.parseElifBranch()
+		if elif == nil {
+			return nil
+		}
+		expression.Elifs = append(expression.Elifs, elif)
+	}
+
 	if p.peekTokenIs(token.ELSE) {
 		p.nextToken()
 
@@ -437,21 +832,62 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+func (p *Parser) parseElifBranch() *ast.ElifBranch {
+	elif := &ast.ElifBranch{
+		Token: p.curToken,
+	}
+
+	if !p.expectPeek(token.LPARENTHESES) {
+		return nil
+	}
+
+	p.nextToken()
+	elif.Condition = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPARENTHESES) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	elif.Consequence = p.parseBlockStatement()
+	return elif
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{
 		Token: p.curToken,
 	}
 	block.Statements = []ast.Statement{}
 
+	defer p.leave()
+	if !p.enter() {
+		return block
+	}
+
+	outerSyncDepth := p.syncDepth
+	p.syncDepth = p.braceDepth
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
 		statement := p.parseStatement()
-		if statement != nil {
+		if p.panicking {
+			p.synchronize()
+			if p.braceDepth < p.syncDepth {
+				// the broken statement already consumed the closing '}'
+				break
+			}
+		} else if statement != nil {
 			block.Statements = append(block.Statements, statement)
 		}
 		p.nextToken()
 	}
+	p.syncDepth = outerSyncDepth
+
+	if p.curTokenIs(token.EOF) {
+		p.expectedError(p.curToken, token.RBRACE)
+	}
 
 	return block
 }
@@ -471,7 +907,12 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
+	// break and continue cannot cross a function boundary
+	outerLoopDepth := p.loopDepth
+	p.loopDepth = 0
 	functionLiteral.Body = p.parseBlockStatement()
+	p.loopDepth = outerLoopDepth
+
 	return functionLiteral
 }
 
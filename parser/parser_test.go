@@ -3,7 +3,9 @@ package parser
 import (
 	"Ahmadi/ast"
 	"Ahmadi/lexer"
+	"Ahmadi/token"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -219,6 +221,164 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteralExpression(t *testing.T) {
+	input := "18446744073709551616;"
+
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	statement := program.Statements[0].(*ast.ExpressionStatement)
+	literal, ok := statement.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.IntegerLiteral. got='%T'", statement.Expression)
+	}
+
+	if literal.Big == nil || literal.Big.String() != "18446744073709551616" {
+		t.Errorf("literal.Big not %s. got=%v", "18446744073709551616", literal.Big)
+	}
+
+	if literal.String() != "18446744073709551616" {
+		t.Errorf("literal.String() wrong. got=%q", literal.String())
+	}
+}
+
+func TestPrefixedIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0o755", 493},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_FF_FF", 65535},
+		{"010", 10},
+	}
+
+	for _, test := range tests {
+		parser := New(lexer.New(test.input))
+		program := parser.ParseProgram()
+		checkParseErrors(t, parser)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := statement.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got='%T'", statement.Expression)
+		}
+
+		if literal.Value != test.expected {
+			t.Errorf("%q: literal.Value not %d. got=%d", test.input, test.expected, literal.Value)
+		}
+	}
+
+	parser := New(lexer.New("0xFFFF_FFFF_FFFF_FFFF_FF"))
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	literal := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if literal.Big == nil || literal.Big.Text(16) != "ffffffffffffffffff" {
+		t.Errorf("literal.Big wrong. got=%v", literal.Big)
+	}
+}
+
+func TestCharLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected rune
+		output   string
+	}{
+		{`'a'`, 'a', `'a'`},
+		{`'\n'`, '\n', `'\n'`},
+		{`'\''`, '\'', `'\''`},
+		{`'é'`, 'é', `'é'`},
+	}
+
+	for _, test := range tests {
+		parser := New(lexer.New(test.input))
+		program := parser.ParseProgram()
+		checkParseErrors(t, parser)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := statement.Expression.(*ast.CharLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.CharLiteral. got='%T'", statement.Expression)
+		}
+
+		if literal.Value != test.expected {
+			t.Errorf("literal.Value not %q. got=%q", test.expected, literal.Value)
+		}
+
+		if literal.String() != test.output {
+			t.Errorf("literal.String() wrong. expected=%q, got=%q", test.output, literal.String())
+		}
+	}
+}
+
+func TestMalformedLiteralDiagnostics(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"def x = 0x;", `hexadecimal literal "0x" has no digits`, 1, 9},
+		{"def x = 1;\ndef y = 12abc;", `malformed number literal "12abc"`, 2, 9},
+		{"def x = 0b12;", `invalid digit '2' in binary literal "0b12"`, 1, 9},
+		{"def c = 'ab';", "character literal 'ab' holds more than one character", 1, 9},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		p.ParseProgram()
+
+		diagnostics := p.Diagnostics()
+		if len(diagnostics) == 0 {
+			t.Fatalf("%q: expected diagnostics, got none", test.input)
+		}
+
+		first := diagnostics[0]
+		if first.Message != test.expectedMessage {
+			t.Errorf("%q: message wrong. expected=%q, got=%q", test.input, test.expectedMessage, first.Message)
+		}
+
+		if first.Start.Line != test.expectedLine || first.Start.Column != test.expectedColumn {
+			t.Errorf("%q: position wrong. expected=%d:%d, got=%s", test.input, test.expectedLine, test.expectedColumn, first.Start)
+		}
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{".5;", 0.5},
+		{"1e-9;", 1e-9},
+		{"6.02E23;", 6.02e23},
+	}
+
+	for _, test := range tests {
+		lexer := lexer.New(test.input)
+		parser := New(lexer)
+		program := parser.ParseProgram()
+		checkParseErrors(t, parser)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := statement.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got='%T'", statement.Expression)
+		}
+
+		if literal.Value != test.expected {
+			t.Errorf("literal.Value not %g. got=%g", test.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -310,6 +470,9 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"9 == 9;", 9, "==", 9},
 		{"12 != 11;", 12, "!=", 11},
 		{"12 / 3;", 12, "/", 3},
+		{"7 >= 3;", 7, ">=", 3},
+		{"3 <= 7;", 3, "<=", 7},
+		{"3 <> 7;", 3, "<>", 7},
 		{"true == true", true, "==", true},
 		{"true != false", true, "!=", false},
 		{"false == false", false, "==", false},
@@ -414,6 +577,62 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"5 < 4 != 3 > 4",
 			"((5 < 4) != (3 > 4))",
 		},
+		{
+			"a + 1 >= b * 2 == c <= d - 1",
+			"(((a + 1) >= (b * 2)) == (c <= (d - 1)))",
+		},
+		{
+			"a >= b <> c <= d",
+			"((a >= b) <> (c <= d))",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a == 1 && b != 2 || c < 3",
+			"(((a == 1) && (b != 2)) || (c < 3))",
+		},
+		{
+			"not a and b or c",
+			"(((not a) and b) or c)",
+		},
+		{
+			"not a == b",
+			"(not (a == b))",
+		},
+		{
+			"not a < b + 1 and c",
+			"((not (a < (b + 1))) and c)",
+		},
+		{
+			"!a == b",
+			"((!a) == b)",
+		},
+		{
+			"not not a or b",
+			"((not (not a)) or b)",
+		},
+		{
+			"!a || b",
+			"((!a) || b)",
+		},
+		{
+			"x = a && b",
+			"(x = (a && b))",
+		},
+		{
+			"a = b = c + 1",
+			"(a = (b = (c + 1)))",
+		},
+		{
+			"a += b * 2",
+			"(a += (b * 2))",
+		},
+		{
+			"x -= 1; y *= 2; z /= 3",
+			"(x -= 1)(y *= 2)(z /= 3)",
+		},
 		{
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
@@ -474,6 +693,62 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a % b * c",
+			"((a % b) * c)",
+		},
+		{
+			"a + b % c",
+			"(a + (b % c))",
+		},
+		{
+			"a ** b ** c",
+			"(a ** (b ** c))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"-a ** b",
+			"(-(a ** b))",
+		},
+		{
+			"a ** -b",
+			"(a ** (-b))",
+		},
+		{
+			"a ** b[0]",
+			"(a ** (b[0]))",
+		},
+		{
+			"~a & b",
+			"((~a) & b)",
+		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b == 0",
+			"((a & b) == 0)",
+		},
+		{
+			"a << 2 + b",
+			"(a << (2 + b))",
+		},
+		{
+			"a & b << c",
+			"(a & (b << c))",
+		},
+		{
+			"a < b << c",
+			"(a < (b << c))",
+		},
+		{
+			"a | b && c",
+			"((a | b) && c)",
+		},
 	}
 
 	for _, test := range tests {
@@ -668,6 +943,71 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
+func TestIfElifElseExpression(t *testing.T) {
+	input := `if (x < y) { x } elif (x > y) { y } elif (x == y) { z } else { w }`
+
+	lex := lexer.New(input)
+	parser := New(lex)
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statement. got=%d", 1, len(program.Statements))
+	}
+
+	statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+
+	exp, ok := statement.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("statement.Expression is not ast.IfExpression. got=%T", statement.Expression)
+	}
+
+	if len(exp.Elifs) != 2 {
+		t.Fatalf("exp.Elifs does not contain %d branches. got=%d", 2, len(exp.Elifs))
+	}
+
+	tests := []struct {
+		operator string
+		result   string
+	}{
+		{">", "y"},
+		{"==", "z"},
+	}
+
+	for i, test := range tests {
+		elif := exp.Elifs[i]
+		if !testInfixExpression(t, elif.Condition, "x", test.operator, "y") {
+			return
+		}
+
+		consequence, ok := elif.Consequence.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("elif.Consequence.Statements[0] is not ast.ExpressionStatement. got=%T", elif.Consequence.Statements[0])
+		}
+
+		if !testIdentifier(t, consequence.Expression, test.result) {
+			return
+		}
+	}
+
+	if exp.Alternative == nil {
+		t.Fatalf("exp.Alternative is nil")
+	}
+
+	expected := "if ((x < y)) { x } elif ((x > y)) { y } elif ((x == y)) { z } else { w }"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+
+	reparsed := New(lexer.New(program.String())).ParseProgram()
+	if reparsed.String() != expected {
+		t.Errorf("String() does not round-trip. got=%q", reparsed.String())
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `fun(x, y) { x + y; }`
 
@@ -979,3 +1319,468 @@ func TestParsingHashLiteralsWithExpressions(t *testing.T) {
 		})
 	}
 }
+
+func TestParserDiagnostics(t *testing.T) {
+	input := "def x 5;\ndef = 10;"
+
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	parser.ParseProgram()
+
+	diagnostics := parser.Diagnostics()
+	if len(diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	first := diagnostics[0]
+	if first.Severity != SeverityError {
+		t.Errorf("first.Severity wrong. expected=%s, got=%s", SeverityError, first.Severity)
+	}
+
+	if first.Expected != token.ASSIGN || first.Found != token.INT {
+		t.Errorf("first expected/found wrong. got expected=%q, found=%q", first.Expected, first.Found)
+	}
+
+	if first.Start.Line != 1 || first.Start.Column != 7 || first.End.Column != 8 {
+		t.Errorf("first position wrong. got start=%s, end=%s", first.Start, first.End)
+	}
+
+	if first.Message != parser.Errors()[0] {
+		t.Errorf("Errors() not in sync with Diagnostics(). got=%q", parser.Errors()[0])
+	}
+
+	expectedSnippet := "1 | def x 5;\n          ^"
+	if first.Snippet(input) != expectedSnippet {
+		t.Errorf("snippet wrong. expected=%q, got=%q", expectedSnippet, first.Snippet(input))
+	}
+
+	rendered := first.SnippetFunc(input, strings.ToUpper)
+	if rendered != "1 | DEF X 5;\n          ^" {
+		t.Errorf("rendered snippet wrong. got=%q", rendered)
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name               string
+		input              string
+		expectedLines      []int
+		expectedStatements int
+	}{
+		{
+			"independent statements",
+			"def x 5;\ndef y = 10;\ndef = 3;\ny;",
+			[]int{1, 3},
+			2,
+		},
+		{
+			"missing semicolon before keyword",
+			"def a = (1 + 2\ndef b = 3;\nreturn b;",
+			[]int{2},
+			2,
+		},
+		{
+			"error inside function body",
+			"def f = fun(x) {\n def = 1;\n return x;\n};\ndef g = 2;",
+			[]int{2},
+			2,
+		},
+		{
+			"error before closing brace",
+			"def f = fun() { 1 + };\ndef g = [1, 2;\ng;",
+			[]int{1, 2},
+			2,
+		},
+		{
+			"hash literal inside block",
+			"if (true) { def h = {\"a\" 1}; h }\nfoo;",
+			[]int{1},
+			2,
+		},
+		{
+			"unterminated block",
+			"def f = fun() { 1",
+			[]int{1},
+			0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lexer := lexer.New(test.input)
+			parser := New(lexer)
+			program := parser.ParseProgram()
+
+			diagnostics := parser.Diagnostics()
+			if len(diagnostics) != len(test.expectedLines) {
+				t.Fatalf("wrong number of diagnostics. expected=%d, got=%d (%v)",
+					len(test.expectedLines), len(diagnostics), parser.Errors())
+			}
+
+			for i, line := range test.expectedLines {
+				if diagnostics[i].Start.Line != line {
+					t.Errorf("diagnostics[%d] on wrong line. expected=%d, got=%d (%s)",
+						i, line, diagnostics[i].Start.Line, diagnostics[i])
+				}
+			}
+
+			if len(program.Statements) != test.expectedStatements {
+				t.Errorf("wrong number of statements. expected=%d, got=%d",
+					test.expectedStatements, len(program.Statements))
+			}
+		})
+	}
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"1 = 2;", "cannot assign to 1"},
+		{"a + b = 2;", "cannot assign to (a + b)"},
+		{"f(x) += 1;", "cannot assign to f(x)"},
+	}
+
+	for _, test := range tests {
+		lexer := lexer.New(test.input)
+		parser := New(lexer)
+		parser.ParseProgram()
+
+		errors := parser.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 error for %q. got=%d (%v)", test.input, len(errors), errors)
+		}
+
+		if errors[0] != test.expectedMessage {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", test.input, test.expectedMessage, errors[0])
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while (x < 10) { x += 1; if (x == 5) { continue; } if (x == 8) { break } }`
+
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statement. got=%d", 1, len(program.Statements))
+	}
+
+	statement, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if !testInfixExpression(t, statement.Condition, "x", "<", 10) {
+		return
+	}
+
+	if len(statement.Body.Statements) != 3 {
+		t.Fatalf("statement.Body.Statements does not contain %d statements. got=%d", 3, len(statement.Body.Statements))
+	}
+
+	continueIf := statement.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if _, ok := continueIf.Consequence.Statements[0].(*ast.ContinueStatement); !ok {
+		t.Errorf("expected ast.ContinueStatement. got=%T", continueIf.Consequence.Statements[0])
+	}
+
+	breakIf := statement.Body.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if _, ok := breakIf.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("expected ast.BreakStatement. got=%T", breakIf.Consequence.Statements[0])
+	}
+}
+
+func TestWhileStatementWithSemicolon(t *testing.T) {
+	input := `while (i < 3) { i += 1; }; def x = 1;`
+
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 2, len(program.Statements))
+	}
+
+	if _, ok := program.Statements[0].(*ast.WhileStatement); !ok {
+		t.Errorf("program.Statements[0] is not ast.WhileStatement. got=%T", program.Statements[0])
+	}
+
+	if _, ok := program.Statements[1].(*ast.DefStatement); !ok {
+		t.Errorf("program.Statements[1] is not ast.DefStatement. got=%T", program.Statements[1])
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"break;", "break outside loop"},
+		{"if (true) { continue; }", "continue outside loop"},
+		{"while (true) { def f = fun() { break; }; }", "break outside loop"},
+	}
+
+	for _, test := range tests {
+		lexer := lexer.New(test.input)
+		parser := New(lexer)
+		parser.ParseProgram()
+
+		errors := parser.Errors()
+		if len(errors) != 1 {
+			t.Fatalf("expected 1 error for %q. got=%d (%v)", test.input, len(errors), errors)
+		}
+
+		if errors[0] != test.expectedMessage {
+			t.Errorf("wrong error for %q. expected=%q, got=%q", test.input, test.expectedMessage, errors[0])
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedKey      string
+		expectedValue    string
+		expectedIterable string
+	}{
+		{"for (x in xs) { x; }", "", "x", "xs"},
+		{"for (k, v in h) { k; }", "k", "v", "h"},
+		{"for (i in range(10)) { break; }", "", "i", "range(10)"},
+	}
+
+	for _, test := range tests {
+		lexer := lexer.New(test.input)
+		parser := New(lexer)
+		program := parser.ParseProgram()
+		checkParseErrors(t, parser)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain %d statement. got=%d", 1, len(program.Statements))
+		}
+
+		statement, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+		}
+
+		if test.expectedKey == "" && statement.Key != nil {
+			t.Errorf("statement.Key is not nil. got=%q", statement.Key)
+		}
+
+		if test.expectedKey != "" && !testIdentifier(t, statement.Key, test.expectedKey) {
+			return
+		}
+
+		if !testIdentifier(t, statement.Value, test.expectedValue) {
+			return
+		}
+
+		if statement.Iterable.String() != test.expectedIterable {
+			t.Errorf("statement.Iterable wrong. expected=%q, got=%q", test.expectedIterable, statement.Iterable.String())
+		}
+	}
+}
+
+func TestForStatementWithSemicolon(t *testing.T) {
+	input := `for (x in xs) { total += x; }; def y = 1;`
+
+	lexer := lexer.New(input)
+	parser := New(lexer)
+	program := parser.ParseProgram()
+	checkParseErrors(t, parser)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d", 2, len(program.Statements))
+	}
+
+	if _, ok := program.Statements[0].(*ast.ForStatement); !ok {
+		t.Errorf("program.Statements[0] is not ast.ForStatement. got=%T", program.Statements[0])
+	}
+
+	if _, ok := program.Statements[1].(*ast.DefStatement); !ok {
+		t.Errorf("program.Statements[1] is not ast.DefStatement. got=%T", program.Statements[1])
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `
+// the answer
+def x = 40 /* almost */ + 2;
+/* disabled:
+def y = x; */
+x;`
+
+	lex := lexer.New(input)
+	p := New(lex)
+	program := p.ParseProgram()
+	checkParseErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
+	}
+
+	if program.String() != "def x = (40 + 2);x" {
+		t.Errorf("program.String() wrong. got=%q", program.String())
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	lex := lexer.New("def x = 1;\n/* no end")
+	p := New(lex)
+	p.ParseProgram()
+
+	diagnostics := p.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. got=%d: %v", len(diagnostics), p.Errors())
+	}
+
+	if diagnostics[0].Start.Line != 2 || diagnostics[0].Start.Column != 1 {
+		t.Errorf("diagnostic position wrong. got=%s", diagnostics[0].Start)
+	}
+
+	if diagnostics[0].Message != "unterminated block comment" {
+		t.Errorf("diagnostic message wrong. got=%q", diagnostics[0].Message)
+	}
+}
+
+func TestStringLiteralDiagnostics(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{`def s = "unterminated;`, "unterminated string", 1, 9},
+		{"def s = 1;\nputs(\"tab\\x\");", `invalid escape sequence "\\x"`, 2, 6},
+		{"def s = `raw", "unterminated raw string", 1, 9},
+		{`def s = "a ${x} \q";`, `invalid escape sequence "\\q"`, 1, 15},
+		{`"a ${x} b`, "unterminated string", 1, 7},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		p.ParseProgram()
+
+		diagnostics := p.Diagnostics()
+		if len(diagnostics) == 0 {
+			t.Fatalf("%q: expected diagnostics, got none", test.input)
+		}
+
+		first := diagnostics[0]
+		if first.Message != test.expectedMessage {
+			t.Errorf("%q: message wrong. expected=%q, got=%q", test.input, test.expectedMessage, first.Message)
+		}
+
+		if first.Start.Line != test.expectedLine || first.Start.Column != test.expectedColumn {
+			t.Errorf("%q: position wrong. expected=%d:%d, got=%s", test.input, test.expectedLine, test.expectedColumn, first.Start)
+		}
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedParts int
+		expected      string
+	}{
+		{`"Hello ${name}!"`, 3, "Hello ${name}!"},
+		{`"${a + b * 2}"`, 1, "${(a + (b * 2))}"},
+		{`"${x}, ${y}"`, 3, "${x}, ${y}"},
+		{`"a ${"b ${c}"} d"`, 3, "a ${b ${c}} d"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		program := p.ParseProgram()
+		checkParseErrors(t, p)
+
+		statement := program.Statements[0].(*ast.ExpressionStatement)
+		str, ok := statement.Expression.(*ast.InterpolatedString)
+		if !ok {
+			t.Fatalf("%s: expression is not *ast.InterpolatedString. got=%T", test.input, statement.Expression)
+		}
+
+		if len(str.Parts) != test.expectedParts {
+			t.Errorf("%s: wrong number of parts. expected=%d, got=%d", test.input, test.expectedParts, len(str.Parts))
+		}
+
+		if str.String() != test.expected {
+			t.Errorf("%s: String() wrong. expected=%q, got=%q", test.input, test.expected, str.String())
+		}
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`"a ${} b"`, "empty interpolation in string"},
+		{`"a ${x y} b"`, "expected '}' to close interpolation in string, got='ID'"},
+		{`"a ${x`, "expected '}' to close interpolation in string, got='EOF'"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != test.expectedMessage {
+			t.Errorf("%s: wrong errors. expected first=%q, got=%v", test.input, test.expectedMessage, p.Errors())
+		}
+	}
+}
+
+func TestSnippetWithUnicode(t *testing.T) {
+	input := `def نام = "علی" @;`
+
+	p := New(lexer.New(input))
+	p.ParseProgram()
+
+	diagnostics := p.Diagnostics()
+	if len(diagnostics) == 0 {
+		t.Fatalf("expected diagnostics, got none")
+	}
+
+	if diagnostics[0].Start.Column != 17 {
+		t.Errorf("column wrong. expected=17, got=%d", diagnostics[0].Start.Column)
+	}
+
+	expected := "1 | " + input + "\n" + strings.Repeat(" ", 4+16) + "^"
+	if snippet := diagnostics[0].Snippet(input); !strings.HasPrefix(snippet, expected) {
+		t.Errorf("snippet wrong.\nexpected=%q\ngot=     %q", expected, snippet)
+	}
+}
+
+func TestNestingLimit(t *testing.T) {
+	deep := MaxNestingDepth + 10
+	tests := []string{
+		strings.Repeat("-", deep) + "1;",
+		strings.Repeat("(", deep) + "1" + strings.Repeat(")", deep) + ";",
+		strings.Repeat("[", deep) + strings.Repeat("]", deep) + ";",
+		strings.Repeat("while (true) {", deep) + strings.Repeat("}", deep),
+	}
+
+	for _, input := range tests {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+
+		diagnostics := p.Diagnostics()
+		if len(diagnostics) != 1 {
+			t.Fatalf("%.20q: expected 1 diagnostic, got %d", input, len(diagnostics))
+		}
+
+		if diagnostics[0].Message != "input is nested too deeply" {
+			t.Errorf("%.20q: message wrong. got=%q", input, diagnostics[0].Message)
+		}
+	}
+
+	shallow := strings.Repeat("-", MaxNestingDepth-1) + "1;"
+	p := New(lexer.New(shallow))
+	p.ParseProgram()
+	checkParseErrors(t, p)
+}
@@ -0,0 +1,226 @@
+package repl
+
+import (
+	"Ahmadi/ast"
+	"Ahmadi/color"
+	"Ahmadi/lexer"
+	"Ahmadi/object"
+	"Ahmadi/token"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// command is a REPL meta-command, typed as ":name argument".
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(s *session, arg string) bool // reports whether the REPL should quit
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"help", ":help", "list the commands", helpCommand},
+		{"env", ":env", "list the bindings of the session", envCommand},
+		{"type", ":type <expr>", "evaluate an expression and show its type", typeCommand},
+		{"ast", ":ast <input>", "show the syntax tree of an input", astCommand},
+		{"tokens", ":tokens <input>", "show the tokens of an input", tokensCommand},
+		{"load", ":load <file>", "run a file in the session", loadCommand},
+		{"reset", ":reset", "forget every binding of the session", resetCommand},
+		{"time", ":time <expr>", "evaluate an expression and show how long it took", timeCommand},
+		{"quit", ":quit", "leave the REPL", quitCommand},
+	}
+}
+
+// runCommand executes a line starting with ':'. It reports whether the
+// REPL should quit.
+func runCommand(s *session, line string) bool {
+	name, arg, _ := strings.Cut(strings.TrimPrefix(line, ":"), " ")
+	arg = strings.TrimSpace(arg)
+
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(s, arg)
+		}
+	}
+
+	io.WriteString(s.out, color.Red(fmt.Sprintf("unknown command %q, type :help for a list\n", ":"+name)))
+	return false
+}
+
+// needArgument reports a usage error when a command was given no argument.
+func needArgument(s *session, arg string, usage string) bool {
+	if arg != "" {
+		return true
+	}
+	io.WriteString(s.out, color.Red("usage: "+usage+"\n"))
+	return false
+}
+
+func usageOf(name string) string {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.usage
+		}
+	}
+	return ":" + name
+}
+
+func helpCommand(s *session, arg string) bool {
+	for _, cmd := range commands {
+		fmt.Fprintf(s.out, "%-18s %s\n", cmd.usage, cmd.help)
+	}
+	return false
+}
+
+func envCommand(s *session, arg string) bool {
+	for _, name := range s.env.Names() {
+		value, _ := s.env.Get(name)
+		fmt.Fprintf(s.out, "%s: %s = %s\n", name, value.Type(), value.Inspect())
+	}
+	return false
+}
+
+func typeCommand(s *session, arg string) bool {
+	if !needArgument(s, arg, usageOf("type")) {
+		return false
+	}
+
+	evaluated := s.eval("", arg)
+	if evaluated == nil {
+		return false
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		s.printError(err)
+		return false
+	}
+
+	io.WriteString(s.out, color.Green(string(evaluated.Type()))+"\n")
+	return false
+}
+
+func astCommand(s *session, arg string) bool {
+	if !needArgument(s, arg, usageOf("ast")) {
+		return false
+	}
+
+	program, ok := s.parse("", arg)
+	if !ok {
+		return false
+	}
+
+	writeTree(s.out, "", program, "")
+	return false
+}
+
+func tokensCommand(s *session, arg string) bool {
+	if !needArgument(s, arg, usageOf("tokens")) {
+		return false
+	}
+
+	lex := lexer.New(arg)
+	for {
+		tok := lex.NextToken()
+		fmt.Fprintf(s.out, "%s\t%s\t%q\n", tok.Pos(), tok.Type, tok.Literal)
+		if tok.Type == token.EOF {
+			return false
+		}
+	}
+}
+
+func loadCommand(s *session, arg string) bool {
+	if !needArgument(s, arg, usageOf("load")) {
+		return false
+	}
+
+	source, err := os.ReadFile(arg)
+	if err != nil {
+		io.WriteString(s.out, color.Red(err.Error()+"\n"))
+		return false
+	}
+
+	if evaluated, ok := s.eval(arg, string(source)).(*object.Error); ok {
+		s.printError(evaluated)
+	}
+	return false
+}
+
+func resetCommand(s *session, arg string) bool {
+	s.env = object.NewEnvironment()
+	return false
+}
+
+func timeCommand(s *session, arg string) bool {
+	if !needArgument(s, arg, usageOf("time")) {
+		return false
+	}
+
+	start := time.Now()
+	evaluated := s.eval("", arg)
+	elapsed := time.Since(start)
+
+	s.printResult(evaluated)
+	io.WriteString(s.out, color.Cyan(fmt.Sprintf("elapsed: %s\n", elapsed)))
+	return false
+}
+
+func quitCommand(s *session, arg string) bool {
+	return true
+}
+
+// writeTree prints node and its children, one per line, indented by
+// depth. Each line shows the field holding the node, its type, position
+// and token.
+func writeTree(out io.Writer, label string, node ast.Node, indent string) {
+	value := reflect.ValueOf(node)
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return
+	}
+
+	if _, ok := node.(*ast.Program); ok {
+		fmt.Fprintf(out, "%s%sProgram\n", indent, label)
+	} else {
+		fmt.Fprintf(out, "%s%s%s %s %q\n", indent, label, value.Elem().Type().Name(), node.Pos(), node.TokenLiteral())
+	}
+
+	indent += "  "
+	value = value.Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Name
+		field := value.Field(i)
+
+		switch field.Kind() {
+		case reflect.Slice:
+			for j := 0; j < field.Len(); j++ {
+				if child, ok := field.Index(j).Interface().(ast.Node); ok {
+					writeTree(out, fmt.Sprintf("%s[%d]: ", name, j), child, indent)
+				}
+			}
+
+		case reflect.Map:
+			keys := field.MapKeys()
+			sort.Slice(keys, func(a, b int) bool {
+				return keys[a].Interface().(ast.Node).Pos().Offset < keys[b].Interface().(ast.Node).Pos().Offset
+			})
+			for _, key := range keys {
+				writeTree(out, "Key: ", key.Interface().(ast.Node), indent)
+				writeTree(out, "Value: ", field.MapIndex(key).Interface().(ast.Node), indent)
+			}
+
+		case reflect.Interface, reflect.Pointer:
+			if field.IsNil() {
+				continue
+			}
+			if child, ok := field.Interface().(ast.Node); ok {
+				writeTree(out, name+": ", child, indent)
+			}
+		}
+	}
+}
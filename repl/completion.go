@@ -0,0 +1,99 @@
+package repl
+
+import (
+	"Ahmadi/evaluator"
+	"Ahmadi/highlight"
+	"Ahmadi/object"
+	"Ahmadi/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hashKeyPrefix matches an index into a named value being typed at the
+// end of a line, as in `person["na`.
+var hashKeyPrefix = regexp.MustCompile(`([\p{L}_][\p{L}\p{N}_]*)\[\s*("[^"]*|[^\]\s"]*)$`)
+
+// complete returns the candidates for the word ending at cursor, and
+// where that word starts. Names come from the session environment, the
+// builtins and the keywords; after `name[` the keys of the hash bound to
+// name are offered instead.
+func (s *session) complete(line []rune, cursor int) (int, []string) {
+	before := string(line[:cursor])
+
+	if match := hashKeyPrefix.FindStringSubmatchIndex(before); match != nil {
+		name := before[match[2]:match[3]]
+		if hash, ok := s.hashNamed(name); ok {
+			prefix := before[match[4]:match[5]]
+			start := cursor - len([]rune(prefix))
+			return start, matching(hashKeys(hash), prefix)
+		}
+	}
+
+	start := cursor
+	for start > 0 && isWordRune(line[start-1]) {
+		start--
+	}
+	if start == cursor {
+		return cursor, nil
+	}
+
+	names := append(s.env.Names(), evaluator.BuiltinNames()...)
+	names = append(names, token.Keywords()...)
+	return start, matching(names, string(line[start:cursor]))
+}
+
+func (s *session) hashNamed(name string) (*object.Hash, bool) {
+	value, ok := s.env.Get(name)
+	if !ok {
+		return nil, false
+	}
+	hash, ok := value.(*object.Hash)
+	return hash, ok
+}
+
+// hashKeys returns the keys of hash as they are written in source,
+// followed by the closing bracket.
+func hashKeys(hash *object.Hash) []string {
+	keys := []string{}
+	for _, pair := range hash.SortedPairs() {
+		key := pair.Key.Inspect()
+		if pair.Key.Type() == object.STRING_OBJ {
+			key = highlight.Quote(key)
+		}
+		keys = append(keys, key+"]")
+	}
+	return keys
+}
+
+// matching returns the sorted, distinct candidates starting with prefix.
+func matching(candidates []string, prefix string) []string {
+	seen := make(map[string]bool)
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) && !seen[candidate] {
+			seen[candidate] = true
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// commonPrefix returns the longest prefix shared by every candidate.
+func commonPrefix(candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	prefix := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		runes := []rune(candidate)
+		i := 0
+		for i < len(prefix) && i < len(runes) && prefix[i] == runes[i] {
+			i++
+		}
+		prefix = prefix[:i]
+	}
+	return string(prefix)
+}
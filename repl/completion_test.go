@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"Ahmadi/object"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	s := &session{env: object.NewEnvironment(), out: io.Discard}
+	s.eval("", `def person = {"name": "ali", "age": 20, 1: true}; def pop_count = 0; def n = 5;`)
+	s.eval("", `def ctrl = {"a\u{7}b": 1, "می\u{200c}خواهم": 2};`)
+	s.eval("", `def odd = {"k\"q": 1, "\\d": 2, "\${x}": 3};`)
+	inner := object.NewEncloseEnvironment(s.env)
+	inner.Set("local", &object.Integer{Value: 1})
+
+	tests := []struct {
+		env        *object.Environment
+		line       string
+		start      int
+		candidates []string
+	}{
+		{s.env, "pop", 0, []string{"pop_back", "pop_count", "pop_front"}},
+		{s.env, "1 + pers", 4, []string{"person"}},
+		{s.env, "el", 0, []string{"elif", "else"}},
+		{s.env, "wh", 0, []string{"while"}},
+		{inner, "lo", 0, []string{"local"}},
+		{inner, "per", 0, []string{"person"}},
+		{s.env, "zzz", 0, []string{}},
+		{s.env, "1 + ", 4, nil},
+		{s.env, "person[", 7, []string{`"age"]`, `"name"]`, "1]"}},
+		{s.env, `person["n`, 7, []string{`"name"]`}},
+		{s.env, `len(person[ "a`, 12, []string{`"age"]`}},
+		{s.env, "n[", 2, nil},
+		{s.env, "odd[", 4, []string{`"\${x}"]`, `"\\d"]`, `"k\"q"]`}},
+		{s.env, `odd["k`, 4, []string{`"k\"q"]`}},
+		{s.env, "ctrl[", 5, []string{`"a\u{7}b"]`, "\"می\u200cخواهم\"]"}},
+	}
+
+	for _, test := range tests {
+		s.env, test.env = test.env, s.env
+		line := []rune(test.line)
+		start, candidates := s.complete(line, len(line))
+		s.env = test.env
+
+		if start != test.start {
+			t.Errorf("complete(%q) start wrong. expected=%d, got=%d", test.line, test.start, start)
+		}
+		if strings.Join(candidates, " ") != strings.Join(test.candidates, " ") {
+			t.Errorf("complete(%q) wrong. expected=%q, got=%q", test.line, test.candidates, candidates)
+		}
+	}
+}
+
+func TestEditorTabCompletion(t *testing.T) {
+	s := &session{env: object.NewEnvironment(), out: io.Discard}
+	s.eval("", `def person = {"name": "ali"}; def counter = 0; def count = 1;`)
+	s.eval("", `def quoted = {"k\"q": 1}; def bell = {"\u{7}": 1};`)
+
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"pers\t\r", "person"},
+		{"person[\t\r", `person["name"]`},
+		{"cou\t\r", "count"},
+		{"count\t\te\r", "counte"},
+		{"\tx\r", "\tx"},
+		{"zzz\t\r", "zzz"},
+		{"le\t(\r", "len("},
+		{"quoted[\t\r", `quoted["k\"q"]`},
+		{"bell[\t\r", `bell["\u{7}"]`},
+	}
+
+	for _, test := range tests {
+		var out strings.Builder
+		e := newEditor(strings.NewReader(test.keys), &out, nil, &history{})
+		e.complete = s.complete
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Errorf("ReadLine(%q) returned error: %s", test.keys, err)
+			continue
+		}
+		if line != test.expected {
+			t.Errorf("ReadLine(%q) wrong. expected=%q, got=%q", test.keys, test.expected, line)
+		}
+	}
+}
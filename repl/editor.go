@@ -0,0 +1,473 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned by ReadLine when the user abandons the line
+// with Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads the input of the REPL one line at a time, showing
+// prompt first.
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// scannerReader reads plain lines, for input that is not a terminal.
+type scannerReader struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func (reader *scannerReader) ReadLine(prompt string) (string, error) {
+	fmt.Fprint(reader.out, prompt)
+	if !reader.scanner.Scan() {
+		if err := reader.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return reader.scanner.Text(), nil
+}
+
+// Keys read by the editor. Control characters are the letter's code
+// minus 0x40; the others stand for escape sequences.
+const (
+	keyCtrlA     = 0x01
+	keyCtrlB     = 0x02
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyCtrlE     = 0x05
+	keyCtrlF     = 0x06
+	keyCtrlG     = 0x07
+	keyCtrlH     = 0x08
+	keyTab       = 0x09
+	keyLineFeed  = 0x0a
+	keyCtrlK     = 0x0b
+	keyCtrlL     = 0x0c
+	keyEnter     = 0x0d
+	keyCtrlN     = 0x0e
+	keyCtrlP     = 0x10
+	keyCtrlR     = 0x12
+	keyCtrlU     = 0x15
+	keyCtrlW     = 0x17
+	keyCtrlY     = 0x19
+	keyEscape    = 0x1b
+	keyBackspace = 0x7f
+
+	keyUp rune = unicode.MaxRune + iota
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyDelete
+	keyWordLeft
+	keyWordRight
+	keyUnknown
+)
+
+// editor is an emacs-style line editor for interactive terminals. It
+// keeps a history that is browsed with up and down and searched with
+// Ctrl-R.
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	raw      func() (func(), error) // switches the terminal to raw mode, if set
+	history  *history
+	complete func(line []rune, cursor int) (int, []string) // completes the word at cursor, if set
+	render   func(line string) string                      // styles the line being edited, if set
+
+	prompt  string
+	buffer  []rune
+	cursor  int
+	killed  []rune // text removed by the last kill, for Ctrl-Y
+	index   int    // position in history, len(entries) for the new line
+	scratch []rune // the new line, while browsing history
+}
+
+func newEditor(in io.Reader, out io.Writer, raw func() (func(), error), history *history) *editor {
+	return &editor{
+		in:      bufio.NewReader(in),
+		out:     out,
+		raw:     raw,
+		history: history,
+	}
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	if e.raw != nil {
+		restore, err := e.raw()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+	}
+
+	e.prompt = prompt
+	e.buffer = nil
+	e.cursor = 0
+	e.index = len(e.history.entries)
+	e.scratch = nil
+	e.refresh()
+
+	line, err := e.edit()
+	if err == nil {
+		e.history.add(line)
+	}
+	return line, err
+}
+
+// edit handles keys until the line is submitted or abandoned.
+func (e *editor) edit() (string, error) {
+	for {
+		key, err := e.readKey()
+		if err != nil {
+			if err == io.EOF && len(e.buffer) != 0 {
+				io.WriteString(e.out, "\r\n")
+				return string(e.buffer), nil
+			}
+			return "", err
+		}
+
+		if key == keyCtrlR {
+			key, err = e.reverseSearch()
+			if err != nil {
+				return "", err
+			}
+		}
+
+		switch key {
+		case keyEnter, keyLineFeed:
+			io.WriteString(e.out, "\r\n")
+			return string(e.buffer), nil
+
+		case keyCtrlC:
+			io.WriteString(e.out, "^C\r\n")
+			return "", errInterrupted
+
+		case keyCtrlD:
+			if len(e.buffer) == 0 {
+				io.WriteString(e.out, "\r\n")
+				return "", io.EOF
+			}
+			e.deleteRange(e.cursor, e.cursor+1)
+
+		case keyDelete:
+			e.deleteRange(e.cursor, e.cursor+1)
+
+		case keyBackspace, keyCtrlH:
+			e.deleteRange(e.cursor-1, e.cursor)
+
+		case keyCtrlA, keyHome:
+			e.cursor = 0
+
+		case keyCtrlE, keyEnd:
+			e.cursor = len(e.buffer)
+
+		case keyCtrlB, keyLeft:
+			if e.cursor > 0 {
+				e.cursor--
+			}
+
+		case keyCtrlF, keyRight:
+			if e.cursor < len(e.buffer) {
+				e.cursor++
+			}
+
+		case keyWordLeft:
+			e.cursor = e.wordStart()
+
+		case keyWordRight:
+			e.cursor = e.wordEnd()
+
+		case keyCtrlK:
+			e.kill(e.cursor, len(e.buffer))
+
+		case keyCtrlU:
+			e.kill(0, e.cursor)
+
+		case keyCtrlW:
+			e.kill(e.wordStart(), e.cursor)
+
+		case keyCtrlY:
+			e.insert(e.killed...)
+
+		case keyCtrlP, keyUp:
+			e.browse(e.index - 1)
+
+		case keyCtrlN, keyDown:
+			e.browse(e.index + 1)
+
+		case keyTab:
+			e.completeWord()
+
+		case keyCtrlL:
+			io.WriteString(e.out, "\x1b[H\x1b[2J")
+
+		default:
+			if unicode.IsPrint(key) {
+				e.insert(key)
+			}
+		}
+
+		e.refresh()
+	}
+}
+
+// completeWord handles Tab. A single candidate replaces the word at the
+// cursor; several extend it to their common prefix, or are listed when
+// they share nothing more. With nothing to complete, a tab is inserted.
+func (e *editor) completeWord() {
+	if e.complete == nil {
+		e.insert(keyTab)
+		return
+	}
+
+	start, candidates := e.complete(e.buffer, e.cursor)
+	if start == e.cursor && len(candidates) == 0 {
+		e.insert(keyTab)
+		return
+	}
+
+	word := string(e.buffer[start:e.cursor])
+	switch len(candidates) {
+	case 0:
+		return
+	case 1:
+		e.deleteRange(start, e.cursor)
+		e.insert([]rune(candidates[0])...)
+	default:
+		if prefix := commonPrefix(candidates); len(prefix) > len(word) {
+			e.deleteRange(start, e.cursor)
+			e.insert([]rune(prefix)...)
+			return
+		}
+		io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+	}
+}
+
+// readKey reads one key press, decoding the escape sequences sent by
+// arrow, home, end and delete keys, and Alt-b / Alt-f.
+func (e *editor) readKey() (rune, error) {
+	key, _, err := e.in.ReadRune()
+	if err != nil || key != keyEscape {
+		return key, err
+	}
+
+	next, _, err := e.in.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+
+	switch next {
+	case 'b':
+		return keyWordLeft, nil
+	case 'f':
+		return keyWordRight, nil
+	case '[', 'O':
+	default:
+		return keyUnknown, nil
+	}
+
+	sequence := []rune{}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		sequence = append(sequence, r)
+		if r >= 0x40 && r <= 0x7e {
+			break
+		}
+	}
+
+	switch string(sequence) {
+	case "A":
+		return keyUp, nil
+	case "B":
+		return keyDown, nil
+	case "C":
+		return keyRight, nil
+	case "D":
+		return keyLeft, nil
+	case "H", "1~", "7~":
+		return keyHome, nil
+	case "F", "4~", "8~":
+		return keyEnd, nil
+	case "3~":
+		return keyDelete, nil
+	case "1;5D", "1;3D":
+		return keyWordLeft, nil
+	case "1;5C", "1;3C":
+		return keyWordRight, nil
+	}
+	return keyUnknown, nil
+}
+
+// reverseSearch runs an incremental search through history, started
+// with Ctrl-R. Typing refines the query and Ctrl-R moves to an older
+// match. Ctrl-G restores the line; any other key keeps the match in the
+// buffer and is returned to be handled as usual.
+func (e *editor) reverseSearch() (rune, error) {
+	original := e.buffer
+	query := []rune{}
+	match := len(e.history.entries)
+
+	for {
+		shown := ""
+		if match < len(e.history.entries) {
+			shown = e.history.entries[match]
+		}
+		fmt.Fprintf(e.out, "\r(reverse-i-search)'%s': %s\x1b[K", string(query), shown)
+
+		key, err := e.readKey()
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case key == keyCtrlR:
+			if found := e.history.search(string(query), match); found >= 0 {
+				match = found
+			}
+
+		case key == keyBackspace || key == keyCtrlH:
+			if len(query) > 0 {
+				query = query[:len(query)-1]
+				match = e.searchFrom(string(query))
+			}
+
+		case key == keyCtrlG:
+			e.buffer = original
+			e.cursor = len(e.buffer)
+			return keyUnknown, nil
+
+		case unicode.IsPrint(key):
+			query = append(query, key)
+			if found := e.history.search(string(query), min(match+1, len(e.history.entries))); found >= 0 {
+				match = found
+			}
+
+		default:
+			if match < len(e.history.entries) {
+				e.buffer = []rune(e.history.entries[match])
+				e.index = match
+			}
+			e.cursor = len(e.buffer)
+			return key, nil
+		}
+	}
+}
+
+// searchFrom finds the newest entry matching query, or returns
+// len(entries) when there is none.
+func (e *editor) searchFrom(query string) int {
+	if query == "" {
+		return len(e.history.entries)
+	}
+	if found := e.history.search(query, len(e.history.entries)); found >= 0 {
+		return found
+	}
+	return len(e.history.entries)
+}
+
+// browse replaces the buffer with the history entry at index, where
+// len(entries) is the line being written.
+func (e *editor) browse(index int) {
+	if index < 0 || index > len(e.history.entries) {
+		return
+	}
+	if e.index == len(e.history.entries) {
+		e.scratch = e.buffer
+	}
+
+	e.index = index
+	if index == len(e.history.entries) {
+		e.buffer = e.scratch
+	} else {
+		e.buffer = []rune(e.history.entries[index])
+	}
+	e.cursor = len(e.buffer)
+}
+
+func (e *editor) insert(runes ...rune) {
+	buffer := make([]rune, 0, len(e.buffer)+len(runes))
+	buffer = append(buffer, e.buffer[:e.cursor]...)
+	buffer = append(buffer, runes...)
+	buffer = append(buffer, e.buffer[e.cursor:]...)
+	e.buffer = buffer
+	e.cursor += len(runes)
+}
+
+// deleteRange removes the runes in [start, end) that are in the buffer.
+func (e *editor) deleteRange(start int, end int) {
+	start = max(start, 0)
+	end = min(end, len(e.buffer))
+	if start >= end {
+		return
+	}
+
+	buffer := make([]rune, 0, len(e.buffer)-(end-start))
+	buffer = append(buffer, e.buffer[:start]...)
+	buffer = append(buffer, e.buffer[end:]...)
+	e.buffer = buffer
+	if e.cursor > end {
+		e.cursor -= end - start
+	} else if e.cursor > start {
+		e.cursor = start
+	}
+}
+
+// kill deletes [start, end) and keeps the text for Ctrl-Y.
+func (e *editor) kill(start int, end int) {
+	if start >= end {
+		return
+	}
+	e.killed = append([]rune(nil), e.buffer[start:end]...)
+	e.deleteRange(start, end)
+}
+
+func (e *editor) wordStart() int {
+	i := e.cursor
+	for i > 0 && !isWordRune(e.buffer[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(e.buffer[i-1]) {
+		i--
+	}
+	return i
+}
+
+func (e *editor) wordEnd() int {
+	i := e.cursor
+	for i < len(e.buffer) && !isWordRune(e.buffer[i]) {
+		i++
+	}
+	for i < len(e.buffer) && isWordRune(e.buffer[i]) {
+		i++
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// refresh redraws the prompt and the buffer, and places the cursor.
+func (e *editor) refresh() {
+	line := strings.ReplaceAll(string(e.buffer), "\t", " ")
+	if e.render != nil {
+		line = e.render(line)
+	}
+	fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, line)
+	if back := len(e.buffer) - e.cursor; back > 0 {
+		fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,119 @@
+package repl
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditorKeys(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"abc\r", "abc"},
+		{"abc\x02\x02X\r", "aXbc"},
+		{"abc\x1b[D\x1b[DX\r", "aXbc"},
+		{"abc\x01X\x05Y\r", "XabcY"},
+		{"abc\x1b[H\x1b[3~\r", "bc"},
+		{"abc\x7f\x7f\r", "a"},
+		{"abc\x01\x04\r", "bc"},
+		{"def x = 1\x17\x17y\r", "def y"},
+		{"one two\x1bb\x0b\r", "one "},
+		{"one two\x1bb\x0b\x01\x19 \r", "two one "},
+		{"one two\x02\x02\x15\x05!\r", "wo!"},
+		{"a\x1bb\x1bfb\r", "ab"},
+		{"héllo\x02\x02\x7f\r", "hélo"},
+		{"abc", "abc"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		e := newEditor(strings.NewReader(test.keys), &out, nil, &history{})
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Errorf("ReadLine(%q) returned error: %s", test.keys, err)
+			continue
+		}
+		if line != test.expected {
+			t.Errorf("ReadLine(%q) wrong. expected=%q, got=%q", test.keys, test.expected, line)
+		}
+	}
+}
+
+func TestEditorInterruptAndEOF(t *testing.T) {
+	e := newEditor(strings.NewReader("abc\x03\x04"), io.Discard, nil, &history{})
+
+	if _, err := e.ReadLine(PROMPT); err != errInterrupted {
+		t.Errorf("Ctrl-C wrong. expected=%v, got=%v", errInterrupted, err)
+	}
+	if _, err := e.ReadLine(PROMPT); err != io.EOF {
+		t.Errorf("Ctrl-D on empty line wrong. expected=%v, got=%v", io.EOF, err)
+	}
+}
+
+func TestEditorHistory(t *testing.T) {
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{"\x1b[A\r", "third"},
+		{"\x10\x10\r", "second"},
+		{"\x10\x10\x10\x10\x10\r", "first"},
+		{"draft\x1b[A\x1b[B\r", "draft"},
+		{"\x10\x10\x0e\r", "third"},
+		{"\x12sec\r", "second"},
+		{"\x12ir\r", "third"},
+		{"\x12ir\x12\r", "first"},
+		{"\x12ir\x12\x7f\x7fse\r", "second"},
+		{"\x12fir\x05!\r", "first!"},
+		{"draft\x12fir\x07\r", "draft"},
+	}
+
+	for _, test := range tests {
+		h := &history{entries: []string{"first", "second", "third"}}
+		e := newEditor(strings.NewReader(test.keys), io.Discard, nil, h)
+
+		line, err := e.ReadLine(PROMPT)
+		if err != nil {
+			t.Errorf("ReadLine(%q) returned error: %s", test.keys, err)
+			continue
+		}
+		if line != test.expected {
+			t.Errorf("ReadLine(%q) wrong. expected=%q, got=%q", test.keys, test.expected, line)
+		}
+	}
+}
+
+func TestHistoryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "history")
+
+	h := loadHistory(path)
+	h.add("def a = 1;")
+	h.add("def a = 1;")
+	h.add("   ")
+	h.add("a + 1")
+
+	loaded := loadHistory(path)
+	expected := []string{"def a = 1;", "a + 1"}
+	if strings.Join(loaded.entries, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("wrong history loaded. expected=%q, got=%q", expected, loaded.entries)
+	}
+
+	lines := make([]string, MAX_HISTORY+10)
+	for i := range lines {
+		lines[i] = strings.Repeat("x", i+1)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded = loadHistory(path)
+	if len(loaded.entries) != MAX_HISTORY || loaded.entries[0] != lines[10] {
+		t.Errorf("history not trimmed to %d entries. got=%d", MAX_HISTORY, len(loaded.entries))
+	}
+}
@@ -0,0 +1,100 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MAX_HISTORY is the number of lines of history kept between sessions.
+const MAX_HISTORY = 1000
+
+// history holds the lines entered in the REPL, oldest first. When path
+// is set, it is loaded from and appended to that file.
+type history struct {
+	entries []string
+	path    string
+}
+
+// historyPath returns the file history is kept in, or "" when there is
+// no config directory.
+func historyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "ahmadi", "history")
+}
+
+// loadHistory reads the history file at path. A missing or unreadable
+// file gives an empty history.
+func loadHistory(path string) *history {
+	h := &history{path: path}
+	if path == "" {
+		return h
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return h
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	file.Close()
+
+	if len(h.entries) > MAX_HISTORY {
+		h.entries = h.entries[len(h.entries)-MAX_HISTORY:]
+		h.save()
+	}
+
+	return h
+}
+
+// add records line, unless it is blank or repeats the previous entry.
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+	if h.path == "" {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	file.WriteString(line + "\n")
+	file.Close()
+}
+
+// save rewrites the history file with the current entries.
+func (h *history) save() {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(h.path, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600)
+}
+
+// search returns the index of the newest entry before index that
+// contains query, or -1.
+func (h *history) search(query string, index int) int {
+	for i := index - 1; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
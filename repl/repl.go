@@ -1,63 +1,191 @@
 package repl
 
 import (
+	"Ahmadi/ast"
 	"Ahmadi/color"
 	"Ahmadi/evaluator"
+	"Ahmadi/highlight"
 	"Ahmadi/lexer"
 	"Ahmadi/object"
 	"Ahmadi/parser"
+	"Ahmadi/token"
 	"bufio"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 const PROMPT string = "APL>> "
 
+// CONTINUATION_PROMPT is shown while reading the rest of an input that
+// spans several lines.
+const CONTINUATION_PROMPT string = "...>> "
+
+// session is the state kept between the inputs of a REPL.
+type session struct {
+	env *object.Environment
+	out io.Writer
+}
+
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	s := &session{env: object.NewEnvironment(), out: out}
+	reader := newLineReader(in, out, s.complete)
 	fmt.Fprint(out, color.Blue("Ahmadi programming language - Copyright (c) 2023 Ali Ahmadi\n\n"))
 
+	pending := ""
+
 	for {
-		fmt.Fprint(out, color.Yellow(PROMPT))
-		scanned := scanner.Scan()
-		if !scanned {
+		prompt := PROMPT
+		if pending != "" {
+			prompt = CONTINUATION_PROMPT
+		}
+
+		line, err := reader.ReadLine(color.Yellow(prompt))
+		if err == errInterrupted {
+			pending = ""
+			continue
+		}
+		if err != nil {
 			return
 		}
 
-		line := scanner.Text()
-		lex := lexer.New(line)
-		parser := parser.New(lex)
-		program := parser.ParseProgram()
-		if len(parser.Errors()) != 0 {
-			printParserErrors(out, parser.Errors())
+		if pending == "" {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" {
+				continue
+			}
+			if trimmed == "exit" {
+				exit(out)
+				return
+			}
+			if strings.HasPrefix(trimmed, ":") {
+				if runCommand(s, trimmed) {
+					exit(out)
+					return
+				}
+				continue
+			}
+		} else if strings.TrimSpace(line) == "" {
+			// an empty line gives up on completing the input
+			line = pending
+		} else {
+			line = pending + "\n" + line
+		}
+
+		if line != pending && isIncomplete(line) {
+			pending = line
 			continue
 		}
+		pending = ""
+
+		s.printResult(s.eval("", line))
+	}
+}
 
-		if program.Statements[0].String() == "exit" {
-			exit(out)
-			os.Exit(0)
+// newLineReader returns a line editor when the REPL runs in a terminal,
+// and reads plain lines otherwise.
+func newLineReader(in io.Reader, out io.Writer, complete func([]rune, int) (int, []string)) lineReader {
+	if file, ok := in.(*os.File); ok && isTerminal(int(file.Fd())) {
+		if _, ok := out.(*os.File); ok {
+			raw := func() (func(), error) { return makeRaw(int(file.Fd())) }
+			e := newEditor(file, out, raw, loadHistory(historyPath()))
+			e.complete = complete
+			e.render = highlight.Code
+			return e
 		}
+	}
+
+	return &scannerReader{scanner: bufio.NewScanner(in), out: out}
+}
+
+// parse parses source, printing its syntax errors. It reports false when
+// there were any.
+func (s *session) parse(fileName string, source string) (*ast.Program, bool) {
+	p := parser.New(lexer.NewFile(fileName, source))
+	program := p.ParseProgram()
+	if len(p.Diagnostics()) != 0 {
+		printParserErrors(s.out, source, p.Diagnostics())
+		return nil, false
+	}
+	return program, true
+}
+
+// eval parses and evaluates source in the session environment. It returns
+// nil when source has syntax errors.
+func (s *session) eval(fileName string, source string) object.Object {
+	program, ok := s.parse(fileName, source)
+	if !ok {
+		return nil
+	}
+	return evaluator.SafeEval(program, s.env)
+}
+
+func (s *session) printResult(evaluated object.Object) {
+	if evaluated == nil {
+		return
+	}
+	if err, ok := evaluated.(*object.Error); ok {
+		s.printError(err)
+		return
+	}
+	io.WriteString(s.out, highlight.Value(evaluated))
+	io.WriteString(s.out, "\n")
+}
+
+func (s *session) printError(err *object.Error) {
+	io.WriteString(s.out, color.Red(err.Traceback()))
+	io.WriteString(s.out, "\n")
+}
+
+// isIncomplete reports whether source is the beginning of a valid input
+// that continues on following lines: it has unclosed brackets, an
+// unterminated block comment, raw or multi-line string, or the parser ran
+// out of input.
+func isIncomplete(source string) bool {
+	lex := lexer.New(source)
+	depth := 0
 
-		evaluated := evaluator.Eval(program, env)
-		if evaluated != nil {
-			if evaluated.Type() != object.ERROR_OBJ {
-				io.WriteString(out, color.Green(evaluated.Inspect()))
-				io.WriteString(out, "\n")
-			} else {
-				io.WriteString(out, color.Red(evaluated.Inspect()))
-				io.WriteString(out, "\n")
+	for tok := lex.NextToken(); tok.Type != token.EOF; tok = lex.NextToken() {
+		switch tok.Type {
+		case token.LPARENTHESES, token.LBRACE, token.LBRACKET:
+			depth++
+		case token.RPARENTHESES, token.RBRACE, token.RBRACKET:
+			depth--
+		case token.ILLEGAL:
+			// block comments, raw and multi-line strings may span lines
+			switch tok.Error {
+			case lexer.UnterminatedBlockComment, lexer.UnterminatedMultiLineString, lexer.UnterminatedRawString:
+				return true
 			}
 		}
 	}
+
+	if depth > 0 {
+		return true
+	}
+
+	p := parser.New(lexer.New(source))
+	p.ParseProgram()
+	for _, diagnostic := range p.Diagnostics() {
+		if diagnostic.Found == token.EOF {
+			return true
+		}
+	}
+
+	return false
 }
 
-func printParserErrors(out io.Writer, errors []string) {
+func printParserErrors(out io.Writer, source string, diagnostics []parser.Diagnostic) {
 	io.WriteString(out, color.Red("Woops!\n"))
 	io.WriteString(out, color.Red(" parser errors:\n"))
-	for _, message := range errors {
-		io.WriteString(out, color.Red("\t"+message+"\n"))
+	for _, diagnostic := range diagnostics {
+		io.WriteString(out, color.Red("\t"+diagnostic.String()+"\n"))
+		if snippet := diagnostic.SnippetFunc(source, highlight.Code); snippet != "" {
+			for _, line := range strings.Split(snippet, "\n") {
+				io.WriteString(out, "\t"+line+"\n")
+			}
+		}
 	}
 	io.WriteString(out, "\n")
 }
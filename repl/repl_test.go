@@ -0,0 +1,120 @@
+package repl
+
+import (
+	"Ahmadi/color"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"def x = 5;", false},
+		{"def f = fun(x) {", true},
+		{"def f = fun(x) {\n\treturn x;\n}", false},
+		{"[1, 2,", true},
+		{`{"a": 1,`, true},
+		{"add(1,", true},
+		{`"unterminated`, false},
+		{"def s = `raw", true},
+		{"def s = \"\"\"first line", true},
+		{"def s = \"\"\"first line\nlast\"\"\";", false},
+		{`"done"`, false},
+		{"1 +", true},
+		{"if (x) { 1 } else", true},
+		{"def x 5;", false},
+		{"1 + )", false},
+		{"def x = 1; /* note", true},
+		{"def x = 1; /* note */", false},
+		{"def x = 1; // note {", false},
+		{`"total: ${add(1,`, true},
+		{`"total: ${x}"`, false},
+		{`def s = """Hello ${name}`, true},
+		{`def s = """Hello ${name}, ${age}`, true},
+		{"def s = `Hello ${name}", true},
+		{`def s = "Hello ${name}`, false},
+		{"def s = \"\"\"Hello ${name}\n!\"\"\";", false},
+	}
+
+	for _, test := range tests {
+		if isIncomplete(test.input) != test.expected {
+			t.Errorf("isIncomplete(%q) wrong. expected=%t", test.input, test.expected)
+		}
+	}
+}
+
+func TestMultiLineInput(t *testing.T) {
+	color.Active = false
+	input := strings.Join([]string{
+		"def add = fun(a, b) {",
+		"  return a + b;",
+		"};",
+		"add(1,",
+		"2)",
+		"[1,",
+		"",
+		"3",
+	}, "\n")
+
+	var out bytes.Buffer
+	Start(strings.NewReader(input), &out)
+
+	output := out.String()
+	expectedPrompts := PROMPT + CONTINUATION_PROMPT + CONTINUATION_PROMPT + "null\n" +
+		PROMPT + CONTINUATION_PROMPT + "3\n" +
+		PROMPT + CONTINUATION_PROMPT + "Woops!"
+
+	if !strings.Contains(output, expectedPrompts) {
+		t.Errorf("unexpected output. expected to contain %q, got=%q", expectedPrompts, output)
+	}
+
+	if !strings.HasSuffix(output, PROMPT+"3\n"+PROMPT) {
+		t.Errorf("input after an abandoned entry not evaluated. got=%q", output)
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	color.Active = false
+	file := filepath.Join(t.TempDir(), "square.apl")
+	if err := os.WriteFile(file, []byte("def square = fun(x) { x * x };"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"def a = 1;\n:env", "a: INTEGER = 1\n"},
+		{":type 1.5", "FLOAT\n"},
+		{":type [1]", "ARRAY\n"},
+		{":type x", "Error: identifier not found: x"},
+		{":ast a + 1", "Program\n  Statements[0]: ExpressionStatement 1:1 \"a\"\n" +
+			"    Expression: InfixExpression 1:1 \"+\"\n" +
+			"      Left: Identifier 1:1 \"a\"\n" +
+			"      Right: IntegerLiteral 1:5 \"1\"\n"},
+		{":ast a +", "parser errors:"},
+		{":tokens a >= 1", "1:1\tID\t\"a\"\n1:3\t>=\t\">=\"\n1:6\tINT\t\"1\"\n1:7\tEOF\t\"\"\n"},
+		{":load " + file + "\nsquare(3)", PROMPT + "9\n"},
+		{":load missing.apl", "missing.apl"},
+		{"def a = 1;\n:reset\na", "identifier not found: a"},
+		{":time 2 + 2", "4\nelapsed: "},
+		{":type", "usage: :type <expr>\n"},
+		{":nope", "unknown command \":nope\""},
+		{":quit\n1", "goodbye :)\n"},
+		{"\n\n1", PROMPT + PROMPT + PROMPT + "1\n"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		Start(strings.NewReader(test.input), &out)
+
+		if !strings.Contains(out.String(), test.expected) {
+			t.Errorf("wrong output for %q. expected to contain %q, got=%q", test.input, test.expected, out.String())
+		}
+	}
+}
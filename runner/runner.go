@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"Ahmadi/evaluator"
+	"Ahmadi/lexer"
+	"Ahmadi/object"
+	"Ahmadi/parser"
+	"fmt"
+	"io"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK           = 0
+	ExitRuntimeError = 1
+	ExitSyntaxError  = 2
+)
+
+// Options control how Run executes a program.
+type Options struct {
+	FileName    string   // name used in diagnostics and tracebacks
+	Args        []string // exposed to the program as the `args` array
+	PrintResult bool     // print the value of the last statement
+}
+
+// Run parses and evaluates source as a whole program. Syntax errors and
+// an uncaught runtime error are reported on errOut, and decide the exit
+// code of the process.
+func Run(source string, options Options, out io.Writer, errOut io.Writer) int {
+	lex := lexer.NewFile(options.FileName, source)
+	p := parser.New(lex)
+	program := p.ParseProgram()
+
+	if len(p.Diagnostics()) != 0 {
+		for _, diagnostic := range p.Diagnostics() {
+			fmt.Fprintln(errOut, diagnostic)
+			if snippet := diagnostic.Snippet(source); snippet != "" {
+				fmt.Fprintln(errOut, snippet)
+			}
+		}
+		return ExitSyntaxError
+	}
+
+	env := object.NewEnvironment()
+	env.Set("args", argsArray(options.Args))
+
+	evaluated := evaluator.SafeEval(program, env)
+	if err, ok := evaluated.(*object.Error); ok {
+		fmt.Fprintln(errOut, err.Traceback())
+		return ExitRuntimeError
+	}
+
+	if options.PrintResult && evaluated != nil && evaluated != evaluator.NULL {
+		fmt.Fprintln(out, evaluated.Inspect())
+	}
+
+	return ExitOK
+}
+
+func argsArray(args []string) *object.Array {
+	elements := make([]object.Object, 0, len(args))
+	for _, arg := range args {
+		elements = append(elements, &object.String{Value: arg})
+	}
+
+	return &object.Array{
+		Elements: elements,
+	}
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name           string
+		source         string
+		options        Options
+		expectedCode   int
+		expectedOut    string
+		expectedErrOut string
+	}{
+		{
+			"multi-line program",
+			"def add = fun(a, b) {\n\treturn a + b;\n};\nadd(1, 2);\n",
+			Options{FileName: "add.apl"},
+			ExitOK,
+			"",
+			"",
+		},
+		{
+			"print result",
+			"def x = 20;\nx * 2",
+			Options{FileName: "<expr>", PrintResult: true},
+			ExitOK,
+			"40\n",
+			"",
+		},
+		{
+			"null result is not printed",
+			"def x = 1;",
+			Options{PrintResult: true},
+			ExitOK,
+			"",
+			"",
+		},
+		{
+			"args binding",
+			"len(args) * 10 + len(args[1])",
+			Options{Args: []string{"a", "bcd"}, PrintResult: true},
+			ExitOK,
+			"23\n",
+			"",
+		},
+		{
+			"runtime error",
+			"def f = fun() {\n\t1 / 0\n};\nf();",
+			Options{FileName: "div.apl"},
+			ExitRuntimeError,
+			"",
+			"Error: division by zero\n    at div.apl:2:2\n    in f called at div.apl:4:1\n",
+		},
+		{
+			"syntax error",
+			"def x 1;",
+			Options{FileName: "bad.apl"},
+			ExitSyntaxError,
+			"",
+			"bad.apl:1:7: error: expected next token to be '=', got='INT'\n1 | def x 1;\n          ^\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			code := Run(test.source, test.options, &out, &errOut)
+
+			if code != test.expectedCode {
+				t.Errorf("wrong exit code. expected=%d, got=%d (stderr=%q)", test.expectedCode, code, errOut.String())
+			}
+
+			if out.String() != test.expectedOut {
+				t.Errorf("wrong output. expected=%q, got=%q", test.expectedOut, out.String())
+			}
+
+			if errOut.String() != test.expectedErrOut {
+				t.Errorf("wrong error output. expected=%q, got=%q", test.expectedErrOut, errOut.String())
+			}
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package token
 
+import (
+	"fmt"
+	"sort"
+)
+
 type TokenType string
 
 type Token struct {
@@ -7,14 +12,69 @@ type Token struct {
 	Literal    string
 	LineNumber int
 	FileName   string
+	Column     int
+	Offset     int
+	EndLine    int
+	EndColumn  int
+	EndOffset  int
+	Comments   []Comment // comments before the token, when the lexer keeps them
+	Error      string    // why the token is ILLEGAL, when the lexer knows
+}
+
+// Comment is a line or block comment, including its delimiters.
+type Comment struct {
+	Text  string
+	Start Position
+	End   Position
+}
+
+// Position is a location in source. Line and Column are 1-based,
+// Offset is the 0-based byte offset into the input.
+type Position struct {
+	FileName string
+	Line     int
+	Column   int
+	Offset   int
+}
+
+func (p Position) String() string {
+	if p.FileName != "" {
+		return fmt.Sprintf("%s:%d:%d", p.FileName, p.Line, p.Column)
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Pos returns the position of the first character of the token.
+func (t Token) Pos() Position {
+	return Position{
+		FileName: t.FileName,
+		Line:     t.LineNumber,
+		Column:   t.Column,
+		Offset:   t.Offset,
+	}
+}
+
+// End returns the position just past the last character of the token.
+func (t Token) End() Position {
+	return Position{
+		FileName: t.FileName,
+		Line:     t.EndLine,
+		Column:   t.EndColumn,
+		Offset:   t.EndOffset,
+	}
 }
 
 const (
 	STRING              = "STRING"
+	STRING_START        = "STRING_START"  // text before the first `${` of a string
+	STRING_MIDDLE       = "STRING_MIDDLE" // text between `}` and the next `${`
+	STRING_END          = "STRING_END"    // text after the last `}`
 	ILLEGAL             = "ILLEGAL"
 	EOF                 = "EOF"
 	ID                  = "ID"
 	INT                 = "INT"
+	FLOAT               = "FLOAT"
+	CHAR                = "CHAR"
 	ASSIGN              = "="
 	PLUS                = "+"
 	COMMA               = ","
@@ -49,17 +109,51 @@ const (
 	LBRACKET            = "["
 	RBRACKET            = "]"
 	COLON               = ":"
+	WHILE               = "WHILE"
+	BREAK               = "BREAK"
+	CONTINUE            = "CONTINUE"
+	FOR                 = "FOR"
+	IN                  = "IN"
+	AND                 = "&&"
+	OR                  = "||"
+	NOT                 = "NOT"
+	PERCENT             = "%"
+	POWER               = "**"
+	BITWISE_AND         = "&"
+	BITWISE_OR          = "|"
+	BITWISE_XOR         = "^"
+	BITWISE_NOT         = "~"
+	SHIFT_LEFT          = "<<"
+	SHIFT_RIGHT         = ">>"
 )
 
 var keywords map[string]TokenType = map[string]TokenType{
-	"fun":    FUNCTION,
-	"def":    DEF,
-	"return": RETURN,
-	"if":     IF,
-	"else":   ELSE,
-	"elif":   ELIF,
-	"true":   TRUE,
-	"false":  FALSE,
+	"fun":      FUNCTION,
+	"def":      DEF,
+	"return":   RETURN,
+	"if":       IF,
+	"else":     ELSE,
+	"elif":     ELIF,
+	"true":     TRUE,
+	"false":    FALSE,
+	"while":    WHILE,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"for":      FOR,
+	"in":       IN,
+	"and":      AND,
+	"or":       OR,
+	"not":      NOT,
+}
+
+// Keywords returns the reserved words of the language, sorted.
+func Keywords() []string {
+	words := make([]string, 0, len(keywords))
+	for word := range keywords {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
 }
 
 func LookupIdentifier(id string) TokenType {